@@ -10,6 +10,34 @@ func runRestoreCommand(args []string) error {
 	return application.RunRestoreCommand(args, cliName())
 }
 
+func runDiffCommand(args []string) error {
+	return application.RunDiffCommand(args, cliName())
+}
+
+func runEditCommand(args []string) error {
+	return application.RunEditCommand(args, cliName())
+}
+
+func runCatCommand(args []string) error {
+	return application.RunCatCommand(args, cliName())
+}
+
+func runGetCommand(args []string) error {
+	return application.RunGetCommand(args, cliName())
+}
+
+func runSetCommand(args []string) error {
+	return application.RunSetCommand(args, cliName())
+}
+
+func runExampleCommand(args []string) error {
+	return application.RunExampleCommand(args, cliName())
+}
+
+func runValidateCommand(args []string) error {
+	return application.RunValidateCommand(args, cliName())
+}
+
 func runVaultCommand(args []string) error {
 	sub := "status"
 	if len(args) > 0 {
@@ -29,6 +57,10 @@ func runRunCommand(args []string) error {
 	return application.RunRunCommand(args, cliName())
 }
 
+func runTfvarsCommand(args []string) error {
+	return application.RunTfvarsCommand(args, cliName())
+}
+
 func parseRunCommandArgs(args []string) []string {
 	return application.ParseRunCommandArgs(args)
 }
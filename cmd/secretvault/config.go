@@ -18,12 +18,11 @@ const (
 )
 
 var (
-	magicHeader          = domain.MagicHeader
-	sensitiveExactNames  = domain.SensitiveExactNames
-	sensitiveSuffixes    = domain.SensitiveSuffixes
-	sensitiveDirNames    = domain.SensitiveDirNames
-	ignoredDirNames      = domain.IgnoredDirNames
-	secretContentPattern = domain.SecretContentPattern
+	magicHeader         = domain.MagicHeader
+	sensitiveExactNames = domain.SensitiveExactNames
+	sensitiveSuffixes   = domain.SensitiveSuffixes
+	sensitiveDirNames   = domain.SensitiveDirNames
+	ignoredDirNames     = domain.IgnoredDirNames
 )
 
 type projectContext = domain.ProjectContext
@@ -36,15 +35,23 @@ func printUsage() {
 	fmt.Println()
 	fmt.Println("Usage:")
 	fmt.Printf("  %s key [set|show|clear] [--value <string> | --generate]\n", name)
-	fmt.Printf("  %s scan [path ...]\n", name)
-	fmt.Printf("  %s lock [--dry-run] [path ...]\n", name)
+	fmt.Printf("  %s scan [--no-ignore] [--entropy-threshold <bits>] [--entropy-min-length <n>] [--max-content-bytes <n>] [--workers <n>] [--archive-max-depth <n>] [--archive-max-bytes <n>] [--source] [--rebuild-index] [--explain] [--format text|json|sarif] [--fail-on-findings] [--history | --staged] [--write-baseline] [--no-baseline] [path ...]\n", name)
+	fmt.Printf("  %s lock [--dry-run] [--dotenv-values] [--structured <pattern,...>] [--tfvars-vars <pattern,...>] [--resign] [path ...]\n", name)
 	fmt.Printf("  %s unlock [--dry-run] [path ...]\n", name)
 	fmt.Printf("  %s restore [--all] [--force] [path ...]\n", name)
+	fmt.Printf("  %s diff [--redact] [--context <n>] [path ...]\n", name)
+	fmt.Printf("  %s edit <path>\n", name)
+	fmt.Printf("  %s cat [--force-tty] <path> ...\n", name)
+	fmt.Printf("  %s get KEY [--file .env] [--json] | get --json [--file .env]\n", name)
+	fmt.Printf("  %s set KEY=VALUE ... [--file .env] [--absorb]\n", name)
+	fmt.Printf("  %s example [--out .env.example] [--check]\n", name)
+	fmt.Printf("  %s validate [--schema .env.schema] [path ...]\n", name)
 	fmt.Printf("  %s absorb [--vault <name>] [--dry-run] [--yes] [path ...]\n", name)
 	fmt.Printf("  %s cleanup [--dry-run] [--yes]\n", name)
 	fmt.Printf("  %s vault status\n", name)
 	fmt.Printf("  %s install [--mode stable-dev|strict] [opencode|claude]\n", name)
-	fmt.Printf("  %s run -- <command> [args ...]\n", name)
+	fmt.Printf("  %s run [--validate] -- <command> [args ...]\n", name)
+	fmt.Printf("  %s tfvars decrypt [--out <dir>] [path ...]\n", name)
 	fmt.Printf("  %s setup [--yes] [--signin-address <address>]\n", name)
 }
 
@@ -1,8 +1,6 @@
 package main
 
 import (
-	"errors"
-	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -23,7 +21,8 @@ func findEncryptedFiles(roots []string) ([]string, error) {
 }
 
 func isSensitiveFile(path string) (bool, error) {
-	return domain.IsSensitiveFile(path)
+	reason, err := domain.IsSensitiveFile(path)
+	return reason.Matched(), err
 }
 
 func hasSensitiveDir(path string) bool {
@@ -36,33 +35,6 @@ func hasSensitiveDir(path string) bool {
 	return false
 }
 
-func looksSensitiveByContent(path string) (bool, error) {
-	info, err := os.Stat(path)
-	if err != nil {
-		return false, err
-	}
-	if info.Size() == 0 || info.Size() > 1<<20 {
-		return false, nil
-	}
-
-	f, err := os.Open(path)
-	if err != nil {
-		return false, err
-	}
-	defer f.Close()
-
-	buf := make([]byte, 4096)
-	n, err := f.Read(buf)
-	if err != nil && !errors.Is(err, io.EOF) {
-		return false, err
-	}
-	if n == 0 {
-		return false, nil
-	}
-
-	return secretContentPattern.Match(buf[:n]), nil
-}
-
 func sortedKeys(in map[string]struct{}) []string {
 	return domain.SortedKeys(in)
 }
@@ -30,12 +30,28 @@ func main() {
 		err = runUnlockCommand(os.Args[2:])
 	case "restore":
 		err = runRestoreCommand(os.Args[2:])
+	case "diff":
+		err = runDiffCommand(os.Args[2:])
+	case "edit":
+		err = runEditCommand(os.Args[2:])
+	case "cat":
+		err = runCatCommand(os.Args[2:])
+	case "get":
+		err = runGetCommand(os.Args[2:])
+	case "set":
+		err = runSetCommand(os.Args[2:])
+	case "example":
+		err = runExampleCommand(os.Args[2:])
+	case "validate":
+		err = runValidateCommand(os.Args[2:])
 	case "vault":
 		err = runVaultCommand(os.Args[2:])
 	case "install":
 		err = runInstallCommand(os.Args[2:])
 	case "run":
 		err = runRunCommand(os.Args[2:])
+	case "tfvars":
+		err = runTfvarsCommand(os.Args[2:])
 	case "absorb":
 		err = runAbsorbCommand(os.Args[2:])
 	case "setup":
@@ -5,6 +5,7 @@ go 1.22
 require (
 	github.com/zalando/go-keyring v0.2.5
 	golang.org/x/term v0.29.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -71,8 +71,12 @@ func RunAbsorbCommand(args []string, cliName string) error {
 		return err
 	}
 
+	opts, err := projectScanOptions(ctx)
+	if err != nil {
+		return err
+	}
 	roots := domain.NormalizeRoots(flags.Args())
-	targets, err := domain.FindSensitiveFiles(roots)
+	targets, err := domain.FindSensitiveFilesWithOptions(roots, opts)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,85 @@
+package application
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+
+	"secrets-vault/internal/domain"
+	"secrets-vault/internal/integrations/opcli"
+)
+
+func RunCatCommand(args []string, cliName string) error {
+	flags := flag.NewFlagSet("cat", flag.ContinueOnError)
+	var forceTTY bool
+	flags.BoolVar(&forceTTY, "force-tty", false, "print plaintext even when stdout is a terminal")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		return fmt.Errorf("usage: %s cat [--force-tty] <path> ...", cliName)
+	}
+	if !forceTTY && term.IsTerminal(int(os.Stdout.Fd())) {
+		return fmt.Errorf("refusing to print secrets to a terminal; pipe the output or pass --force-tty")
+	}
+
+	ctx, err := domain.LoadProjectContext()
+	if err != nil {
+		return err
+	}
+	for _, path := range flags.Args() {
+		entry, target, err := trackedEntry(ctx, path, cliName)
+		if err != nil {
+			return err
+		}
+		plaintext, err := resolvePlaintext(ctx, entry, target, cliName)
+		if err != nil {
+			return err
+		}
+		if _, err := os.Stdout.Write(plaintext); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func resolvePlaintext(ctx domain.ProjectContext, entry domain.VaultEntry, target, cliName string) ([]byte, error) {
+	if domain.FileExists(target) {
+		if !domain.IsValueLockMode(entry.LockMode) {
+			return os.ReadFile(target)
+		}
+		key, err := loadProjectKey(ctx, cliName)
+		if err != nil {
+			return nil, err
+		}
+		return workingPlaintext(entry, target, key)
+	}
+
+	source, found, err := domain.ResolveLocalRestoreSource(ctx, entry, target)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		key, err := loadProjectKey(ctx, cliName)
+		if err != nil {
+			return nil, err
+		}
+		payload, err := os.ReadFile(source)
+		if err != nil {
+			return nil, err
+		}
+		plaintext, _, err := domain.DecryptPayload(payload, key)
+		if err != nil {
+			return nil, fmt.Errorf("decrypt %s: %w", source, err)
+		}
+		return plaintext, nil
+	}
+
+	if strings.TrimSpace(entry.OnePasswordDocument) != "" {
+		return opcli.ReadDocument(entry)
+	}
+	return nil, fmt.Errorf("no local or 1Password copy of %s", target)
+}
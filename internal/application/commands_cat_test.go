@@ -0,0 +1,148 @@
+package application
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"secrets-vault/internal/domain"
+	"secrets-vault/internal/integrations/keyringstore"
+)
+
+func TestRunCatCommandResolvesEveryLockMode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake op is a shell script")
+	}
+	t.Setenv("SECRETVAULT_HOME", t.TempDir())
+	t.Setenv("SECRETVAULT_KEYRING_FALLBACK", "file")
+	bin := t.TempDir()
+	script := "#!/bin/sh\ncase \"$1 $2\" in\n\"account list\") echo '[{\"url\":\"example.1password.com\"}]' ;;\n\"document get\") printf 'from 1password\\n' ;;\nesac\n"
+	if err := os.WriteFile(filepath.Join(bin, "op"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin)
+
+	dir := t.TempDir()
+	chdir(t, dir)
+	ctx, err := domain.LoadProjectContext()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := bytes.Repeat([]byte{9}, 32)
+	if err := keyringstore.SaveProjectKey(ctx, key); err != nil {
+		t.Fatal(err)
+	}
+
+	fileLock := func(name, content string) string {
+		t.Helper()
+		target := filepath.Join(ctx.ProjectPath, name)
+		payload, err := domain.EncryptPayload([]byte(content), key, 0o600)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := domain.WriteAtomic(target+domain.EncryptedExt, payload, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := domain.UpsertVaultEntry(ctx, target, target+domain.EncryptedExt, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return target
+	}
+	dropLocalCopies := func(target string) domain.VaultEntry {
+		t.Helper()
+		entry, _, err := trackedEntry(ctx, target, "secretvault")
+		if err != nil {
+			t.Fatal(err)
+		}
+		backup, err := domain.EntryVaultBackupPath(ctx, entry)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, path := range []string{target + domain.EncryptedExt, backup} {
+			if err := os.Remove(path); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return entry
+	}
+
+	sealed := fileLock("sealed.txt", "from vault copy\n")
+	unlocked := fileLock("unlocked.txt", "stale vault copy\n")
+	if err := domain.WriteAtomic(unlocked, []byte("from working tree\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	dotenv := filepath.Join(ctx.ProjectPath, ".env")
+	lock := domain.ValueLock{Mode: domain.LockModeDotenvValues}
+	locked, _, err := domain.LockValues(lock, dotenv, []byte("TOKEN=from-values\n"), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := domain.WriteAtomic(dotenv, locked, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := domain.UpsertValueLockedVaultEntry(ctx, dotenv, []byte("TOKEN=from-values\n"), key, 0o600, lock); err != nil {
+		t.Fatal(err)
+	}
+
+	remote := fileLock("remote.txt", "stale vault copy\n")
+	dropLocalCopies(remote)
+	manifest, manifestPath, err := domain.LoadVaultManifest(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := manifest.Entries[remote]
+	entry.OnePasswordDocument = "doc-remote"
+	manifest.Entries[remote] = entry
+	if err := domain.SaveVaultManifest(manifestPath, manifest); err != nil {
+		t.Fatal(err)
+	}
+
+	out := captureStdout(t, func() error {
+		return RunCatCommand([]string{"sealed.txt", "unlocked.txt", ".env", "remote.txt"}, "secretvault")
+	})
+	want := "from vault copy\nfrom working tree\nTOKEN=from-values\nfrom 1password\n"
+	if out != want {
+		t.Fatalf("unexpected cat output:\n%s\nwant:\n%s", out, want)
+	}
+
+	if _, err := resolvePlaintext(ctx, dropLocalCopies(sealed), sealed, "secretvault"); err == nil {
+		t.Fatal("expected an error when neither a local nor a 1Password copy exists")
+	}
+}
+
+func chdir(t *testing.T, dir string) {
+	t.Helper()
+	prev, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(prev) })
+}
+
+func captureStdout(t *testing.T, run func() error) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	runErr := run()
+	os.Stdout = stdout
+	w.Close()
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runErr != nil {
+		t.Fatalf("run: %v", runErr)
+	}
+	return string(out)
+}
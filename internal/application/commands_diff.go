@@ -0,0 +1,129 @@
+package application
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"secrets-vault/internal/domain"
+)
+
+func RunDiffCommand(args []string, cliName string) error {
+	flags := flag.NewFlagSet("diff", flag.ContinueOnError)
+	var redact bool
+	var context int
+	flags.BoolVar(&redact, "redact", false, "show only which keys changed, never values")
+	flags.IntVar(&context, "context", domain.DiffContextLines, "lines of context around each change")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if context < 0 {
+		return errors.New("--context must not be negative")
+	}
+
+	ctx, err := domain.LoadProjectContext()
+	if err != nil {
+		return err
+	}
+	manifest, _, err := domain.LoadVaultManifest(ctx)
+	if err != nil {
+		return err
+	}
+	entries := domain.SelectRestoreEntries(ctx, manifest, flags.Args(), true)
+	if len(entries) == 0 {
+		if flags.NArg() > 0 {
+			return errors.New("no tracked files match the given paths")
+		}
+		fmt.Println("No tracked files.")
+		return nil
+	}
+	key, err := loadProjectKey(ctx, cliName)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		target := domain.ResolveEntryTargetPath(ctx, entry)
+		display := entry.RelativePath
+		if display == "" {
+			display = target
+		}
+
+		vaulted, err := vaultedPlaintext(ctx, entry, key)
+		if err != nil {
+			return fmt.Errorf("%s: %w", display, err)
+		}
+		current, err := workingPlaintext(entry, target, key)
+		if errors.Is(err, os.ErrNotExist) {
+			if flags.NArg() > 0 {
+				fmt.Printf("%s: not unlocked, nothing to compare\n", display)
+			}
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", display, err)
+		}
+		if bytes.Equal(vaulted, current) {
+			continue
+		}
+
+		if redact {
+			printRedactedDiff(display, target, vaulted, current)
+			continue
+		}
+		fmt.Print(domain.UnifiedDiff(display+" (vault)", display, vaulted, current, context))
+	}
+	return nil
+}
+
+func vaultedPlaintext(ctx domain.ProjectContext, entry domain.VaultEntry, key []byte) ([]byte, error) {
+	backup, err := domain.EntryVaultBackupPath(ctx, entry)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := os.ReadFile(backup)
+	if err != nil {
+		return nil, fmt.Errorf("read vault backup: %w", err)
+	}
+	plaintext, _, err := domain.DecryptPayload(payload, key)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt vault backup: %w", err)
+	}
+	return plaintext, nil
+}
+
+func workingPlaintext(entry domain.VaultEntry, target string, key []byte) ([]byte, error) {
+	data, err := os.ReadFile(target)
+	if err != nil {
+		return nil, err
+	}
+	if domain.IsValueLockMode(entry.LockMode) {
+		data, _, err = domain.UnlockValues(entry.LockMode, target, data, key)
+	}
+	return data, err
+}
+
+func printRedactedDiff(display, target string, vaulted, current []byte) {
+	changes, ok := domain.RedactedKeyDiff(target, vaulted, current)
+	if !ok {
+		fmt.Printf("%s: content changed (values hidden)\n", display)
+		return
+	}
+	if len(changes) == 0 {
+		fmt.Printf("%s: formatting changed, no values differ\n", display)
+		return
+	}
+	fmt.Printf("%s:\n", display)
+	for _, change := range changes {
+		marker := "~"
+		switch change.Change {
+		case domain.KeyAdded:
+			marker = "+"
+		case domain.KeyRemoved:
+			marker = "-"
+		}
+		fmt.Printf("  %s %s (%s)\n", marker, change.Key, change.Change)
+	}
+}
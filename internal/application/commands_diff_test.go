@@ -0,0 +1,54 @@
+package application
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"secrets-vault/internal/domain"
+	"secrets-vault/internal/integrations/keyringstore"
+)
+
+func TestRunDiffCommandHonoursRedactAndContext(t *testing.T) {
+	t.Setenv("SECRETVAULT_HOME", t.TempDir())
+	t.Setenv("SECRETVAULT_KEYRING_FALLBACK", "file")
+	dir := t.TempDir()
+	chdir(t, dir)
+	ctx, err := domain.LoadProjectContext()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := bytes.Repeat([]byte{5}, 32)
+	if err := keyringstore.SaveProjectKey(ctx, key); err != nil {
+		t.Fatal(err)
+	}
+
+	target := filepath.Join(ctx.ProjectPath, ".env")
+	vaulted := []byte("A=1\nB=2\nC=3\nD=4\nE=5\nF=6\n")
+	lock := domain.ValueLock{Mode: domain.LockModeDotenvValues}
+	if err := domain.UpsertValueLockedVaultEntry(ctx, target, vaulted, key, 0o600, lock); err != nil {
+		t.Fatal(err)
+	}
+	if err := domain.WriteAtomic(target, []byte("A=1\nB=2\nC=3\nD=4\nE=5\nF=changed-secret\nG=7\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	out := captureStdout(t, func() error { return RunDiffCommand([]string{"--context", "1"}, "secretvault") })
+	want := "--- .env (vault)\n+++ .env\n@@ -5,2 +5,3 @@\n E=5\n-F=6\n+F=changed-secret\n+G=7\n"
+	if out != want {
+		t.Fatalf("unexpected diff:\n%s\nwant:\n%s", out, want)
+	}
+
+	out = captureStdout(t, func() error { return RunDiffCommand([]string{"--redact"}, "secretvault") })
+	if strings.Contains(out, "changed-secret") || strings.Contains(out, "=6") {
+		t.Fatalf("redacted diff leaked a value:\n%s", out)
+	}
+	if !strings.Contains(out, "~ F (changed)") || !strings.Contains(out, "+ G (added)") {
+		t.Fatalf("expected changed and added keys in redacted diff:\n%s", out)
+	}
+
+	if err := RunDiffCommand([]string{"--context", "-1"}, "secretvault"); err == nil {
+		t.Fatal("expected a negative --context to be rejected")
+	}
+}
@@ -0,0 +1,186 @@
+package application
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"secrets-vault/internal/domain"
+)
+
+func RunEditCommand(args []string, cliName string) error {
+	flags := flag.NewFlagSet("edit", flag.ContinueOnError)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return fmt.Errorf("usage: %s edit <path>", cliName)
+	}
+
+	ctx, err := domain.LoadProjectContext()
+	if err != nil {
+		return err
+	}
+	entry, target, err := trackedEntry(ctx, flags.Arg(0), cliName)
+	if err != nil {
+		return err
+	}
+	key, err := loadProjectKey(ctx, cliName)
+	if err != nil {
+		return err
+	}
+
+	plaintext, err := lockedPlaintext(ctx, entry, target, key)
+	if err != nil {
+		return err
+	}
+
+	dir, err := domain.PrivateTempDir("secretvault-edit-")
+	if err != nil {
+		return err
+	}
+	defer domain.ShredDir(dir)
+	scratch := filepath.Join(dir, filepath.Base(target))
+	if err := domain.WriteAtomic(scratch, plaintext, 0o600); err != nil {
+		return err
+	}
+
+	if err := runEditor(scratch); err != nil {
+		return err
+	}
+	edited, err := os.ReadFile(scratch)
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(edited, plaintext) {
+		fmt.Printf("no changes to %s\n", target)
+		return nil
+	}
+
+	if err := saveLockedPlaintext(ctx, entry, target, edited, key); err != nil {
+		return err
+	}
+	fmt.Printf("updated %s\n", target)
+	warnStaleOnePassword(entry)
+	return nil
+}
+
+func warnStaleOnePassword(entry domain.VaultEntry) {
+	if strings.TrimSpace(entry.OnePasswordDocument) != "" {
+		fmt.Printf("1Password document %s still holds the previous version; absorb the file again to update it\n", entry.OnePasswordDocument)
+	}
+}
+
+func trackedEntry(ctx domain.ProjectContext, path, cliName string) (domain.VaultEntry, string, error) {
+	manifest, _, err := domain.LoadVaultManifest(ctx)
+	if err != nil {
+		return domain.VaultEntry{}, "", err
+	}
+	entries := domain.SelectRestoreEntries(ctx, manifest, []string{strings.TrimSuffix(path, domain.EncryptedExt)}, true)
+	switch len(entries) {
+	case 0:
+		return domain.VaultEntry{}, "", fmt.Errorf("%s is not tracked. run: %s lock %s", path, cliName, path)
+	case 1:
+		return entries[0], domain.ResolveEntryTargetPath(ctx, entries[0]), nil
+	}
+	return domain.VaultEntry{}, "", fmt.Errorf("%s matches %d tracked files; use the project-relative path", path, len(entries))
+}
+
+func lockedPlaintext(ctx domain.ProjectContext, entry domain.VaultEntry, target string, key []byte) ([]byte, error) {
+	if domain.IsValueLockMode(entry.LockMode) {
+		return workingPlaintext(entry, target, key)
+	}
+	if domain.FileExists(target) {
+		return nil, fmt.Errorf("%s is unlocked; edit it in place and run lock", target)
+	}
+	source, found, err := domain.ResolveLocalRestoreSource(ctx, entry, target)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("no local encrypted copy of %s", target)
+	}
+	payload, err := os.ReadFile(source)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, _, err := domain.DecryptPayload(payload, key)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt %s: %w", source, err)
+	}
+	return plaintext, nil
+}
+
+func saveLockedPlaintext(ctx domain.ProjectContext, entry domain.VaultEntry, target string, plaintext, key []byte) error {
+	mode := fileModeOrDefault(entry.OriginalMode)
+
+	if domain.IsValueLockMode(entry.LockMode) {
+		lock := domain.ValueLock{Mode: entry.LockMode, Variables: entry.LockVariables}
+		locked, _, err := domain.LockValues(lock, target, plaintext, key)
+		if err != nil {
+			return err
+		}
+		if info, err := os.Stat(target); err == nil {
+			mode = info.Mode().Perm()
+		}
+		if err := domain.WriteAtomic(target, locked, mode); err != nil {
+			return err
+		}
+		if err := domain.UpsertValueLockedVaultEntry(ctx, target, plaintext, key, mode, lock); err != nil {
+			return err
+		}
+		return domain.UpdateEditedVaultEntry(ctx, target, entry)
+	}
+
+	encrypted := target + domain.EncryptedExt
+	if !domain.FileExists(encrypted) && entry.ProjectEncryptedFile != "" {
+		encrypted = entry.ProjectEncryptedFile
+	}
+	payload, err := domain.EncryptPayload(plaintext, key, mode)
+	if err != nil {
+		return err
+	}
+	if err := domain.WriteAtomic(encrypted, payload, 0o600); err != nil {
+		return err
+	}
+	if err := domain.UpsertVaultEntry(ctx, target, encrypted, mode); err != nil {
+		return err
+	}
+	return domain.UpdateEditedVaultEntry(ctx, target, entry)
+}
+
+func fileModeOrDefault(mode uint32) fs.FileMode {
+	if perm := fs.FileMode(mode).Perm(); perm != 0 {
+		return perm
+	}
+	return 0o600
+}
+
+func runEditor(path string) error {
+	editor := strings.TrimSpace(os.Getenv("EDITOR"))
+	if editor == "" {
+		editor = strings.TrimSpace(os.Getenv("VISUAL"))
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	parts := strings.Fields(editor)
+	cmd := exec.Command(parts[0], append(parts[1:], path)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return fmt.Errorf("editor exited with status %d; changes discarded", exitErr.ExitCode())
+		}
+		return fmt.Errorf("start editor %q: %w", parts[0], err)
+	}
+	return nil
+}
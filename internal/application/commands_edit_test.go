@@ -0,0 +1,92 @@
+package application
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"secrets-vault/internal/domain"
+)
+
+func TestSaveLockedPlaintextRelocksAndKeepsMetadata(t *testing.T) {
+	t.Setenv("SECRETVAULT_HOME", t.TempDir())
+	dir := t.TempDir()
+	ctx := domain.ProjectContext{ProjectPath: dir, ProjectID: "edit-test", KeyID: "project-edit-test"}
+	key := bytes.Repeat([]byte{7}, 32)
+
+	fileTarget := filepath.Join(dir, "service.json")
+	payload, err := domain.EncryptPayload([]byte(`{"token":"old"}`), key, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := domain.WriteAtomic(fileTarget+domain.EncryptedExt, payload, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := domain.UpsertVaultEntry(ctx, fileTarget, fileTarget+domain.EncryptedExt, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	valueTarget := filepath.Join(dir, ".env")
+	lock := domain.ValueLock{Mode: domain.LockModeDotenvValues}
+	locked, _, err := domain.LockValues(lock, valueTarget, []byte("A=old\n"), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := domain.WriteAtomic(valueTarget, locked, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := domain.UpsertValueLockedVaultEntry(ctx, valueTarget, []byte("A=old\n"), key, 0o600, lock); err != nil {
+		t.Fatal(err)
+	}
+
+	manifest, manifestPath, err := domain.LoadVaultManifest(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for path, entry := range manifest.Entries {
+		entry.OnePasswordDocument = "doc-" + filepath.Base(path)
+		entry.AbsorbedAt = "2026-01-01T00:00:00Z"
+		entry.ChecksumSHA256 = "checksum-of-" + filepath.Base(path)
+		manifest.Entries[path] = entry
+	}
+	if err := domain.SaveVaultManifest(manifestPath, manifest); err != nil {
+		t.Fatal(err)
+	}
+
+	for target, edited := range map[string]string{fileTarget: `{"token":"new"}`, valueTarget: "A=new\nB=added\n"} {
+		entry, _, err := trackedEntry(ctx, target, "secretvault")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := saveLockedPlaintext(ctx, entry, target, []byte(edited), key); err != nil {
+			t.Fatalf("save %s: %v", target, err)
+		}
+
+		saved, _, err := trackedEntry(ctx, target, "secretvault")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if saved.LockMode != entry.LockMode {
+			t.Fatalf("%s: lock mode changed from %q to %q", target, entry.LockMode, saved.LockMode)
+		}
+		if saved.OnePasswordDocument != entry.OnePasswordDocument || saved.AbsorbedAt != entry.AbsorbedAt || saved.ChecksumSHA256 != entry.ChecksumSHA256 {
+			t.Fatalf("%s: expected 1Password metadata to stay until the next absorb, got %+v", target, saved)
+		}
+		plaintext, err := lockedPlaintext(ctx, saved, target, key)
+		if err != nil || string(plaintext) != edited {
+			t.Fatalf("%s: expected edited plaintext back, got %q err=%v", target, plaintext, err)
+		}
+	}
+
+	if domain.FileExists(fileTarget) {
+		t.Fatal("file-locked edit must not write plaintext into the project")
+	}
+	onDisk, err := os.ReadFile(valueTarget)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(onDisk, []byte("new")) || bytes.Contains(onDisk, []byte("added")) || !domain.HasDotenvTokens(onDisk) {
+		t.Fatalf("expected only sealed values in %s: %s", valueTarget, onDisk)
+	}
+}
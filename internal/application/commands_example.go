@@ -0,0 +1,115 @@
+package application
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"secrets-vault/internal/domain"
+)
+
+const defaultExampleFile = ".env.example"
+
+func RunExampleCommand(args []string, cliName string) error {
+	flags := flag.NewFlagSet("example", flag.ContinueOnError)
+	var out string
+	var check bool
+	flags.StringVar(&out, "out", defaultExampleFile, "example file to write (- for stdout)")
+	flags.BoolVar(&check, "check", false, "fail when the example is missing keys from the tracked dotenv files")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 {
+		return fmt.Errorf("usage: %s example [--out %s] [--check]", cliName, defaultExampleFile)
+	}
+
+	ctx, err := domain.LoadProjectContext()
+	if err != nil {
+		return err
+	}
+	sources, err := trackedDotenvSources(ctx, out, cliName)
+	if err != nil {
+		return err
+	}
+	if len(sources) == 0 {
+		return fmt.Errorf("no tracked dotenv files. run: %s lock .env", cliName)
+	}
+
+	if check {
+		data, err := os.ReadFile(out)
+		if os.IsNotExist(err) {
+			return fmt.Errorf("%s does not exist. run: %s example --out %s", out, cliName, out)
+		}
+		if err != nil {
+			return err
+		}
+		example, err := domain.ParseDotenv(data)
+		if err != nil {
+			return fmt.Errorf("parse %s: %w", out, err)
+		}
+		missing := domain.MissingDotenvKeys(example, sources)
+		for _, m := range missing {
+			fmt.Printf("missing %s (from %s)\n", m.Key, m.Source)
+		}
+		if len(missing) > 0 {
+			return fmt.Errorf("%s is missing %d key(s). run: %s example --out %s", out, len(missing), cliName, out)
+		}
+		fmt.Printf("%s covers all keys from %d tracked dotenv file(s)\n", out, len(sources))
+		return nil
+	}
+
+	data := append([]byte(fmt.Sprintf("# Generated by %s example; values are placeholders.\n\n", cliName)), domain.DotenvExample(sources)...)
+	if out == "-" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+	if err := domain.WriteAtomic(out, data, 0o644); err != nil {
+		return err
+	}
+	fmt.Printf("wrote %s from %d tracked dotenv file(s)\n", out, len(sources))
+	return nil
+}
+
+func trackedDotenvSources(ctx domain.ProjectContext, exclude, cliName string) ([]domain.DotenvExampleSource, error) {
+	manifest, _, err := domain.LoadVaultManifest(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if exclude != "" {
+		if exclude, err = filepath.Abs(exclude); err != nil {
+			return nil, err
+		}
+	}
+
+	var sources []domain.DotenvExampleSource
+	for _, k := range domain.SortedVaultEntryKeys(manifest) {
+		entry := manifest.Entries[k]
+		target := domain.ResolveEntryTargetPath(ctx, entry)
+		if !domain.IsDotenvFile(target) || domain.IsDotenvExample(target) || domain.IsDotenvSchema(target) || target == exclude {
+			continue
+		}
+		source, err := dotenvSource(ctx, entry, target, cliName)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, source)
+	}
+	return sources, nil
+}
+
+func dotenvSource(ctx domain.ProjectContext, entry domain.VaultEntry, target, cliName string) (domain.DotenvExampleSource, error) {
+	plaintext, err := resolvePlaintext(ctx, entry, target, cliName)
+	if err != nil {
+		return domain.DotenvExampleSource{}, err
+	}
+	dotenv, err := domain.ParseDotenv(plaintext)
+	if err != nil {
+		return domain.DotenvExampleSource{}, fmt.Errorf("parse %s: %w", target, err)
+	}
+	label := target
+	if rel, ok := domain.ProjectRelativePath(ctx.ProjectPath, target); ok {
+		label = rel
+	}
+	return domain.DotenvExampleSource{Path: label, File: dotenv}, nil
+}
@@ -0,0 +1,85 @@
+package application
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"secrets-vault/internal/domain"
+)
+
+const defaultDotenvFile = ".env"
+
+func RunGetCommand(args []string, cliName string) error {
+	flags := flag.NewFlagSet("get", flag.ContinueOnError)
+	var file string
+	var asJSON bool
+	flags.StringVar(&file, "file", defaultDotenvFile, "tracked dotenv file to read")
+	flags.BoolVar(&asJSON, "json", false, "print values as a JSON object (all keys unless KEY is given)")
+	positional, err := parseInterspersed(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 1 || (len(positional) == 0 && !asJSON) {
+		return fmt.Errorf("usage: %s get KEY [--file %s] | %s get --json [--file %s]", cliName, defaultDotenvFile, cliName, defaultDotenvFile)
+	}
+
+	ctx, err := domain.LoadProjectContext()
+	if err != nil {
+		return err
+	}
+	dotenv, err := loadTrackedDotenv(ctx, file, cliName)
+	if err != nil {
+		return err
+	}
+
+	values := dotenv.Values()
+	if len(positional) == 1 {
+		name := positional[0]
+		entry, ok := dotenv.Lookup(name)
+		if !ok {
+			return fmt.Errorf("%s is not set in %s", name, file)
+		}
+		values = map[string]string{name: entry.Value}
+		if !asJSON {
+			fmt.Println(entry.Value)
+			return nil
+		}
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return enc.Encode(values)
+}
+
+func loadTrackedDotenv(ctx domain.ProjectContext, file, cliName string) (*domain.DotenvFile, error) {
+	entry, target, err := trackedEntry(ctx, file, cliName)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := resolvePlaintext(ctx, entry, target, cliName)
+	if err != nil {
+		return nil, err
+	}
+	dotenv, err := domain.ParseDotenv(plaintext)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", file, err)
+	}
+	return dotenv, nil
+}
+
+func parseInterspersed(flags *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, err
+		}
+		if flags.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, flags.Arg(0))
+		args = flags.Args()[1:]
+	}
+}
@@ -0,0 +1,84 @@
+package application
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"secrets-vault/internal/domain"
+	"secrets-vault/internal/integrations/keyringstore"
+)
+
+func TestParseInterspersedAcceptsFlagsAfterPositionals(t *testing.T) {
+	flags := flag.NewFlagSet("get", flag.ContinueOnError)
+	file := flags.String("file", ".env", "")
+	asJSON := flags.Bool("json", false, "")
+
+	positional, err := parseInterspersed(flags, []string{"DATABASE_URL", "--file", ".env.prod", "--json"})
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if !reflect.DeepEqual(positional, []string{"DATABASE_URL"}) || *file != ".env.prod" || !*asJSON {
+		t.Fatalf("unexpected parse result: %v file=%s json=%v", positional, *file, *asJSON)
+	}
+}
+
+func TestRunGetCommandReadsLockedDotenvFiles(t *testing.T) {
+	t.Setenv("SECRETVAULT_HOME", t.TempDir())
+	t.Setenv("SECRETVAULT_KEYRING_FALLBACK", "file")
+	chdir(t, t.TempDir())
+	ctx, err := domain.LoadProjectContext()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := bytes.Repeat([]byte{3}, 32)
+	if err := keyringstore.SaveProjectKey(ctx, key); err != nil {
+		t.Fatal(err)
+	}
+
+	valueLocked := filepath.Join(ctx.ProjectPath, ".env")
+	plain := []byte("API_TOKEN=abc123\nexport REGION=eu\n")
+	lock := domain.ValueLock{Mode: domain.LockModeDotenvValues}
+	locked, _, err := domain.LockValues(lock, valueLocked, plain, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := domain.WriteAtomic(valueLocked, locked, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := domain.UpsertValueLockedVaultEntry(ctx, valueLocked, plain, key, 0o600, lock); err != nil {
+		t.Fatal(err)
+	}
+
+	fileLocked := filepath.Join(ctx.ProjectPath, ".env.prod")
+	payload, err := domain.EncryptPayload([]byte("API_TOKEN=prod-token\n"), key, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := domain.WriteAtomic(fileLocked+domain.EncryptedExt, payload, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := domain.UpsertVaultEntry(ctx, fileLocked, fileLocked+domain.EncryptedExt, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if out := captureStdout(t, func() error { return RunGetCommand([]string{"API_TOKEN"}, "secretvault") }); out != "abc123\n" {
+		t.Fatalf("unexpected value from value-locked .env: %q", out)
+	}
+	if out := captureStdout(t, func() error { return RunGetCommand([]string{"--json", "--file", ".env.prod"}, "secretvault") }); out != "{\n  \"API_TOKEN\": \"prod-token\"\n}\n" {
+		t.Fatalf("unexpected JSON from file-locked .env.prod: %q", out)
+	}
+	if err := RunGetCommand([]string{"MISSING"}, "secretvault"); err == nil {
+		t.Fatal("expected an error for an unset key")
+	}
+	if domain.FileExists(fileLocked) {
+		t.Fatal("get must not restore file-locked plaintext into the project")
+	}
+	onDisk, err := os.ReadFile(valueLocked)
+	if err != nil || !bytes.Equal(onDisk, locked) {
+		t.Fatalf("get must leave the value-locked file untouched, err=%v", err)
+	}
+}
@@ -1,9 +1,11 @@
 package application
 
 import (
+	"bytes"
 	"errors"
 	"flag"
 	"fmt"
+	"os"
 	"path/filepath"
 	"sort"
 	"strings"
@@ -98,37 +100,22 @@ func RunKeyCommand(args []string, cliName string) error {
 	}
 }
 
-func RunScanCommand(args []string) error {
-	flags := flag.NewFlagSet("scan", flag.ContinueOnError)
-	if err := flags.Parse(args); err != nil {
-		return err
-	}
-
-	roots := domain.NormalizeRoots(flags.Args())
-	targets, err := domain.FindSensitiveFiles(roots)
-	if err != nil {
-		return err
-	}
-
-	if len(targets) == 0 {
-		fmt.Println("No sensitive files detected.")
-		return nil
-	}
-
-	for _, t := range targets {
-		fmt.Println(t)
-	}
-	fmt.Printf("Detected %d sensitive file(s).\n", len(targets))
-	return nil
-}
-
 func RunLockCommand(args []string, cliName string) error {
 	flags := flag.NewFlagSet("lock", flag.ContinueOnError)
 	var dryRun bool
+	modes := defaultLockModes()
+	structured := strings.Join(modes.structuredPatterns, ",")
+	tfvarsVariables := strings.Join(modes.tfvarsVariables, ",")
 	flags.BoolVar(&dryRun, "dry-run", false, "show files that would be encrypted")
+	flags.BoolVar(&modes.dotenvValues, "dotenv-values", modes.dotenvValues, "encrypt .env values in place instead of replacing the whole file")
+	flags.StringVar(&structured, "structured", structured, "comma-separated YAML/JSON file patterns whose leaf values are encrypted in place")
+	flags.StringVar(&tfvarsVariables, "tfvars-vars", tfvarsVariables, "comma-separated variable name patterns whose string values are encrypted in .tfvars files")
+	flags.BoolVar(&modes.resign, "resign", false, "re-sign structured files whose MAC no longer matches (after reviewing the change)")
 	if err := flags.Parse(args); err != nil {
 		return err
 	}
+	modes.structuredPatterns = domain.SplitLockPatterns(structured)
+	modes.tfvarsVariables = domain.SplitLockPatterns(tfvarsVariables)
 
 	ctx, err := domain.LoadProjectContext()
 	if err != nil {
@@ -143,8 +130,12 @@ func RunLockCommand(args []string, cliName string) error {
 		return err
 	}
 
+	opts, err := projectScanOptions(ctx)
+	if err != nil {
+		return err
+	}
 	roots := domain.NormalizeRoots(flags.Args())
-	targets, err := domain.FindSensitiveFiles(roots)
+	targets, err := domain.FindSensitiveFilesWithOptions(roots, opts)
 	if err != nil {
 		return err
 	}
@@ -157,17 +148,13 @@ func RunLockCommand(args []string, cliName string) error {
 		return nil
 	}
 
-	count, err := lockTargets(ctx, key, targets, dryRun)
-	if err != nil {
-		return err
-	}
-
+	count, err := lockTargets(ctx, key, targets, dryRun, modes)
 	if dryRun {
 		fmt.Printf("Would lock %d file(s).\n", count)
-		return nil
+		return err
 	}
 	fmt.Printf("Locked %d file(s).\n", count)
-	return nil
+	return err
 }
 
 func RunUnlockCommand(args []string, cliName string) error {
@@ -196,12 +183,17 @@ func RunUnlockCommand(args []string, cliName string) error {
 	if err != nil {
 		return err
 	}
-	if len(targets) == 0 {
+	valueTargets, err := valueLockedTargets(ctx, roots)
+	if err != nil {
+		return err
+	}
+	if len(targets) == 0 && len(valueTargets) == 0 {
 		fmt.Println("No encrypted files detected to unlock.")
 		return nil
 	}
 
 	count := 0
+	var errs []error
 	for _, path := range targets {
 		dst := strings.TrimSuffix(path, domain.EncryptedExt)
 		if dryRun {
@@ -211,23 +203,79 @@ func RunUnlockCommand(args []string, cliName string) error {
 		}
 
 		if _, err := domain.DecryptFile(path, key); err != nil {
-			return fmt.Errorf("decrypt %s: %w", path, err)
+			errs = append(errs, fmt.Errorf("decrypt %s: %w", path, err))
+			continue
 		}
 		fmt.Printf("unlocked %s\n", dst)
 		count++
 	}
+	for _, entry := range valueTargets {
+		path := domain.ResolveEntryTargetPath(ctx, entry)
+		if dryRun {
+			fmt.Printf("[dry-run] decrypt values in %s\n", path)
+			count++
+			continue
+		}
+		if _, err := unlockValues(path, entry.LockMode, key); err != nil {
+			errs = append(errs, fmt.Errorf("decrypt values in %s: %w", path, err))
+			continue
+		}
+		fmt.Printf("unlocked %s\n", path)
+		count++
+	}
 
 	if dryRun {
 		fmt.Printf("Would unlock %d file(s).\n", count)
 		return nil
 	}
 	fmt.Printf("Unlocked %d file(s).\n", count)
-	return nil
+	return errors.Join(errs...)
 }
 
-func lockTargets(ctx domain.ProjectContext, key []byte, targets []string, dryRun bool) (int, error) {
+func projectScanOptions(ctx domain.ProjectContext) (domain.ScanOptions, error) {
+	opts := domain.DefaultScanOptions()
+	indexPath, err := domain.ScanIndexPath(ctx)
+	if err != nil {
+		return opts, err
+	}
+	opts.IndexPath = indexPath
+	baseline, err := domain.LoadBaseline(ctx.ProjectPath)
+	if err != nil {
+		return opts, err
+	}
+	opts.Baseline = baseline
+	return opts, nil
+}
+
+func lockTargets(ctx domain.ProjectContext, key []byte, targets []string, dryRun bool, modes lockModes) (int, error) {
+	manifest, _, err := domain.LoadVaultManifest(ctx)
+	if err != nil {
+		return 0, err
+	}
+
 	count := 0
+	var errs []error
 	for _, path := range targets {
+		if lock := lockModeFor(ctx, manifest, path, modes); domain.IsValueLockMode(lock.Mode) {
+			lock.Resign = modes.resign
+			locked, err := lockValues(ctx, key, path, lock, dryRun)
+			if errors.Is(err, domain.ErrStructuredMACMismatch) {
+				errs = append(errs, fmt.Errorf("encrypt values in %s: %w; review the file, then run: lock --resign %s", path, err, path))
+				continue
+			}
+			if err == nil {
+				if locked {
+					count++
+				}
+				continue
+			}
+			if !unparsableDotenv(path, lock, err) {
+				errs = append(errs, fmt.Errorf("encrypt values in %s: %w", path, err))
+				continue
+			}
+			fmt.Printf("cannot parse %s as dotenv (%v); locking the whole file\n", path, err)
+		}
+
 		dst := path + domain.EncryptedExt
 		if dryRun {
 			fmt.Printf("[dry-run] %s -> %s\n", path, dst)
@@ -237,15 +285,28 @@ func lockTargets(ctx domain.ProjectContext, key []byte, targets []string, dryRun
 
 		encryptedPath, originalMode, err := domain.EncryptFile(path, key)
 		if err != nil {
-			return count, fmt.Errorf("encrypt %s: %w", path, err)
+			errs = append(errs, fmt.Errorf("encrypt %s: %w", path, err))
+			continue
 		}
 		if err := domain.UpsertVaultEntry(ctx, path, encryptedPath, originalMode); err != nil {
-			return count, fmt.Errorf("track vault entry %s: %w", path, err)
+			errs = append(errs, fmt.Errorf("track vault entry %s: %w", path, err))
+			continue
 		}
 		fmt.Printf("locked %s\n", path)
 		count++
 	}
-	return count, nil
+	return count, errors.Join(errs...)
+}
+
+// unparsableDotenv reports a dotenv file that value mode cannot parse and that
+// holds no sealed values yet, so locking it whole loses nothing.
+func unparsableDotenv(path string, lock domain.ValueLock, err error) bool {
+	var syntaxErr *domain.DotenvSyntaxError
+	if lock.Mode != domain.LockModeDotenvValues || !errors.As(err, &syntaxErr) {
+		return false
+	}
+	data, readErr := os.ReadFile(path)
+	return readErr == nil && !bytes.Contains(data, []byte(domain.ValueTokenPrefix))
 }
 
 func mergeTrackedLockTargets(ctx domain.ProjectContext, scanTargets []string) ([]string, error) {
@@ -282,3 +343,147 @@ func mergeTrackedLockTargets(ctx domain.ProjectContext, scanTargets []string) ([
 	sort.Strings(out)
 	return out, nil
 }
+
+type lockModes struct {
+	dotenvValues       bool
+	structuredPatterns []string
+	tfvarsVariables    []string
+	resign             bool
+}
+
+func defaultLockModes() lockModes {
+	return lockModes{
+		dotenvValues:       strings.EqualFold(strings.TrimSpace(os.Getenv("SECRETVAULT_DOTENV_MODE")), "values"),
+		structuredPatterns: domain.StructuredLockPatterns(),
+		tfvarsVariables:    domain.TfvarsVariables(),
+	}
+}
+
+func lockModeFor(ctx domain.ProjectContext, manifest domain.VaultManifest, path string, modes lockModes) domain.ValueLock {
+	if abs, err := filepath.Abs(path); err == nil {
+		if entry, ok := manifest.Entries[abs]; ok && domain.IsValueLockMode(entry.LockMode) {
+			if entry.LockMode == domain.LockModeTfvarsValues && len(modes.tfvarsVariables) > 0 {
+				return domain.ValueLock{Mode: entry.LockMode, Variables: modes.tfvarsVariables}
+			}
+			return domain.ValueLock{Mode: entry.LockMode, Variables: entry.LockVariables}
+		}
+	}
+	if len(modes.tfvarsVariables) > 0 && domain.IsTfvarsFile(path) && hasTfvarsVariables(path, modes.tfvarsVariables) {
+		return domain.ValueLock{Mode: domain.LockModeTfvarsValues, Variables: modes.tfvarsVariables}
+	}
+	if modes.dotenvValues && domain.IsDotenvFile(path) {
+		return domain.ValueLock{Mode: domain.LockModeDotenvValues}
+	}
+	if domain.MatchesLockPattern(ctx.ProjectPath, path, modes.structuredPatterns) {
+		return domain.ValueLock{Mode: domain.LockModeStructuredValues}
+	}
+	return domain.ValueLock{Mode: domain.LockModeFile}
+}
+
+func hasTfvarsVariables(path string, variables []string) bool {
+	data, err := os.ReadFile(path)
+	return err == nil && domain.HasTfvarsVariables(data, variables)
+}
+
+func lockValues(ctx domain.ProjectContext, key []byte, path string, lock domain.ValueLock, dryRun bool) (bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return false, err
+	}
+	locked, count, err := domain.LockValues(lock, path, data, key)
+	if err != nil {
+		return false, err
+	}
+	resigned := lock.Resign && count == 0 && !bytes.Equal(locked, data)
+	if count == 0 && !resigned {
+		if !domain.HasLockedValues(lock.Mode, path, data) {
+			fmt.Printf("skipped %s (no values to encrypt)\n", path)
+		}
+		return false, nil
+	}
+	plaintext, _, err := domain.UnlockValues(lock.Mode, path, locked, key)
+	if err != nil {
+		return false, err
+	}
+	if dryRun {
+		if resigned {
+			fmt.Printf("[dry-run] re-sign %s\n", path)
+		} else {
+			fmt.Printf("[dry-run] encrypt %d value(s) in %s\n", count, path)
+		}
+		return true, nil
+	}
+
+	if err := domain.WriteAtomic(path, locked, info.Mode().Perm()); err != nil {
+		return false, err
+	}
+	if err := domain.UpsertValueLockedVaultEntry(ctx, path, plaintext, key, info.Mode().Perm(), lock); err != nil {
+		return false, fmt.Errorf("track vault entry: %w", err)
+	}
+	if resigned {
+		fmt.Printf("re-signed %s\n", path)
+	} else {
+		fmt.Printf("locked %s (%d value(s))\n", path, count)
+	}
+	return true, nil
+}
+
+func unlockValues(path, mode string, key []byte) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, err
+	}
+	plaintext, count, err := domain.UnlockValues(mode, path, data, key)
+	var syntaxErr *domain.DotenvSyntaxError
+	if errors.As(err, &syntaxErr) {
+		return 0, fmt.Errorf("%w; fix the file, then unlock it again", err)
+	}
+	if err != nil || count == 0 {
+		return 0, err
+	}
+	return count, domain.WriteAtomic(path, plaintext, info.Mode().Perm())
+}
+
+func valueLockedTargets(ctx domain.ProjectContext, roots []string) ([]domain.VaultEntry, error) {
+	manifest, _, err := domain.LoadVaultManifest(ctx)
+	if err != nil {
+		return nil, err
+	}
+	absRoots := make([]string, 0, len(roots))
+	for _, root := range roots {
+		abs, err := filepath.Abs(root)
+		if err != nil {
+			return nil, err
+		}
+		absRoots = append(absRoots, abs)
+	}
+
+	var out []domain.VaultEntry
+	for _, k := range domain.SortedVaultEntryKeys(manifest) {
+		entry := manifest.Entries[k]
+		target := domain.ResolveEntryTargetPath(ctx, entry)
+		if !pathWithinRoots(target, absRoots) || !domain.EntryNeedsValueDecrypt(entry, target) {
+			continue
+		}
+		out = append(out, entry)
+	}
+	return out, nil
+}
+
+func pathWithinRoots(path string, absRoots []string) bool {
+	for _, root := range absRoots {
+		rel, err := filepath.Rel(root, path)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
@@ -21,6 +21,10 @@ import (
 )
 
 func RunRestoreCommand(args []string, cliName string) error {
+	return restoreTrackedFiles(args, cliName, nil)
+}
+
+func restoreTrackedFiles(args []string, cliName string, skip func(entry domain.VaultEntry, target string) bool) error {
 	flags := flag.NewFlagSet("restore", flag.ContinueOnError)
 	var restoreAll bool
 	var force bool
@@ -49,8 +53,38 @@ func RunRestoreCommand(args []string, cliName string) error {
 	now := time.Now().UTC().Format(time.RFC3339)
 	var key []byte
 	keyLoaded := false
+	loadKey := func() error {
+		if keyLoaded {
+			return nil
+		}
+		key, err = keyringstore.LoadProjectKey(ctx)
+		if err != nil {
+			if errors.Is(err, keyring.ErrNotFound) {
+				return fmt.Errorf("missing key for this project. run: %s key set", cliName)
+			}
+			return err
+		}
+		keyLoaded = true
+		return nil
+	}
 	for _, entry := range entries {
 		target := domain.ResolveEntryTargetPath(ctx, entry)
+		if skip != nil && skip(entry, target) {
+			continue
+		}
+		if domain.EntryNeedsValueDecrypt(entry, target) {
+			if err := loadKey(); err != nil {
+				return err
+			}
+			if _, err := unlockValues(target, entry.LockMode, key); err != nil {
+				return fmt.Errorf("restore %s: %w", target, err)
+			}
+			entry.LastRestoredAt = now
+			manifest.Entries[entry.AbsolutePath] = entry
+			fmt.Printf("restored %s (values decrypted)\n", target)
+			count++
+			continue
+		}
 		if domain.FileExists(target) && !force {
 			fmt.Printf("skip %s (already exists, use --force to overwrite)\n", target)
 			continue
@@ -62,15 +96,8 @@ func RunRestoreCommand(args []string, cliName string) error {
 		}
 
 		if sourceFound {
-			if !keyLoaded {
-				key, err = keyringstore.LoadProjectKey(ctx)
-				if err != nil {
-					if errors.Is(err, keyring.ErrNotFound) {
-						return fmt.Errorf("missing key for this project. run: %s key set", cliName)
-					}
-					return err
-				}
-				keyLoaded = true
+			if err := loadKey(); err != nil {
+				return err
 			}
 
 			if err := domain.RestorePlaintextFromEncrypted(source, target, key, fs.FileMode(entry.OriginalMode), force); err != nil {
@@ -133,11 +160,27 @@ func RunVaultStatusCommand() error {
 			display = entry.AbsolutePath
 		}
 		hasOnePassword := strings.TrimSpace(entry.OnePasswordDocument) != ""
+		if domain.IsValueLockMode(entry.LockMode) {
+			locked := domain.EntryNeedsValueDecrypt(entry, target)
+			fmt.Printf("- %s | values:%s backup:%s op:%s mode:%s\n", display, lockedLabel(locked, domain.FileExists(target)), domain.YesNo(domain.FileExists(vaultBackup)), domain.YesNo(hasOnePassword), entry.LockMode)
+			continue
+		}
 		fmt.Printf("- %s | plain:%s project:%s backup:%s op:%s\n", display, domain.YesNo(domain.FileExists(target)), domain.YesNo(domain.FileExists(projectEncrypted)), domain.YesNo(domain.FileExists(vaultBackup)), domain.YesNo(hasOnePassword))
 	}
 	return nil
 }
 
+func lockedLabel(locked, exists bool) string {
+	switch {
+	case !exists:
+		return "missing"
+	case locked:
+		return "encrypted"
+	default:
+		return "plain"
+	}
+}
+
 func RunInstallCommand(args []string) error {
 	target, mode, err := parseInstallArgsInternal(args, false)
 	if err != nil {
@@ -192,7 +235,11 @@ func runGuidedInstallWorkflow() error {
 		return err
 	}
 
-	discovered, err := domain.FindSensitiveFiles([]string{"."})
+	opts, err := projectScanOptions(ctx)
+	if err != nil {
+		return err
+	}
+	discovered, err := domain.FindSensitiveFilesWithOptions([]string{"."}, opts)
 	if err != nil {
 		return err
 	}
@@ -271,7 +318,7 @@ func runGuidedInstallWorkflow() error {
 			return err
 		}
 	} else {
-		if _, err := lockTargets(ctx, key, selected, false); err != nil {
+		if _, err := lockTargets(ctx, key, selected, false, defaultLockModes()); err != nil {
 			return err
 		}
 	}
@@ -425,6 +472,10 @@ func defaultValue(value, fallback string) string {
 }
 
 func RunRunCommand(args []string, cliName string) error {
+	validate := runValidateRequested()
+	if len(args) > 0 && args[0] == "--validate" {
+		validate, args = true, args[1:]
+	}
 	commandArgs := ParseRunCommandArgs(args)
 	if len(commandArgs) == 0 {
 		if !isInteractiveTerminal() {
@@ -440,7 +491,25 @@ func RunRunCommand(args []string, cliName string) error {
 		}
 	}
 
-	if err := RunRestoreCommand(nil, cliName); err != nil {
+	if validate {
+		ctx, err := domain.LoadProjectContext()
+		if err != nil {
+			return err
+		}
+		if err := validateTrackedDotenv(ctx, "", nil, cliName, false); err != nil {
+			return fmt.Errorf("%w; not starting %s", err, commandArgs[0])
+		}
+	}
+
+	commandArgs, cleanupTfvars, err := prepareRuntimeTfvars(cliName, commandArgs)
+	if err != nil {
+		return fmt.Errorf("prepare runtime tfvars: %w", err)
+	}
+	defer cleanupTfvars()
+	keepTfvarsLocked := func(entry domain.VaultEntry, target string) bool {
+		return entry.LockMode == domain.LockModeTfvarsValues && domain.EntryNeedsValueDecrypt(entry, target)
+	}
+	if err := restoreTrackedFiles(nil, cliName, keepTfvarsLocked); err != nil {
 		return fmt.Errorf("prepare runtime secrets: %w", err)
 	}
 
@@ -451,6 +520,7 @@ func RunRunCommand(args []string, cliName string) error {
 	cmd.Env = append(os.Environ(), "SECRETVAULT_RUNTIME=1")
 
 	runErr := cmd.Run()
+	cleanupTfvars()
 	lockErr := RunLockCommand(nil, cliName)
 
 	if runErr != nil && lockErr != nil {
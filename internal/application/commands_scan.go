@@ -0,0 +1,339 @@
+package application
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"secrets-vault/internal/domain"
+	"secrets-vault/internal/integrations/gitcli"
+)
+
+func RunScanCommand(args []string) error {
+	flags := flag.NewFlagSet("scan", flag.ContinueOnError)
+	ctx, err := domain.LoadProjectContext()
+	if err != nil {
+		return err
+	}
+	opts, err := projectScanOptions(ctx)
+	if err != nil {
+		return err
+	}
+	var explain bool
+	var format string
+	var failOnFindings bool
+	var history bool
+	var staged bool
+	var writeBaseline bool
+	var noBaseline bool
+	flags.BoolVar(&opts.NoIgnore, "no-ignore", false, "do not honour .gitignore and .secretvaultignore files")
+	flags.Float64Var(&opts.EntropyThreshold, "entropy-threshold", opts.EntropyThreshold, "minimum Shannon entropy (bits/char) for a value to count as a secret (0 disables)")
+	flags.IntVar(&opts.EntropyMinLength, "entropy-min-length", opts.EntropyMinLength, "minimum value length for entropy detection")
+	flags.Int64Var(&opts.MaxContentBytes, "max-content-bytes", opts.MaxContentBytes, "read at most this many bytes per file during content scanning (0 = no limit)")
+	flags.IntVar(&opts.Workers, "workers", opts.Workers, "number of parallel scan workers")
+	flags.IntVar(&opts.ArchiveMaxDepth, "archive-max-depth", opts.ArchiveMaxDepth, "how many levels of nested zip/tar archives to open (0 disables archive scanning)")
+	flags.Int64Var(&opts.ArchiveMaxBytes, "archive-max-bytes", opts.ArchiveMaxBytes, "maximum uncompressed bytes to read per archive")
+	flags.BoolVar(&opts.ScanSource, "source", opts.ScanSource, "also scan source code files (.go, .py, .ts, .tf, ...) with the named detectors")
+	flags.BoolVar(&opts.RebuildIndex, "rebuild-index", false, "ignore the cached scan index and re-evaluate every file")
+	flags.BoolVar(&explain, "explain", false, "show which rule flagged each file")
+	flags.StringVar(&format, "format", scanFormatText, "output format: text, json or sarif")
+	flags.BoolVar(&failOnFindings, "fail-on-findings", false, "exit with a non-zero status when sensitive files are detected")
+	flags.BoolVar(&history, "history", false, "scan every commit of the local git repository instead of the working tree")
+	flags.BoolVar(&staged, "staged", false, "scan files staged in the git index instead of the working tree")
+	flags.BoolVar(&writeBaseline, "write-baseline", false, "record all current findings in "+domain.BaselineFileName)
+	flags.BoolVar(&noBaseline, "no-baseline", false, "report findings even if they are recorded in "+domain.BaselineFileName)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if format != scanFormatText && format != scanFormatJSON && format != scanFormatSARIF {
+		return fmt.Errorf("invalid format %q (expected %s, %s or %s)", format, scanFormatText, scanFormatJSON, scanFormatSARIF)
+	}
+	if history && staged {
+		return errors.New("--history and --staged cannot be combined")
+	}
+	if writeBaseline && (history || staged) {
+		return errors.New("--write-baseline only works on the working tree")
+	}
+	if noBaseline {
+		opts.Baseline = nil
+	}
+	opts.IncludeSuppressed = explain && format == scanFormatText
+
+	roots := domain.NormalizeRoots(flags.Args())
+	if writeBaseline {
+		return writeScanBaseline(ctx, roots, opts)
+	}
+	var findings []scanFinding
+	switch {
+	case history:
+		findings, err = scanGitHistory(roots, opts)
+	case staged:
+		findings, err = scanGitIndex(roots, opts)
+	default:
+		var detections []domain.Detection
+		detections, err = domain.ScanSensitiveFiles(roots, opts)
+		findings = findingsFromDetections(detections)
+	}
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case scanFormatJSON:
+		if err := writeScanJSON(os.Stdout, ctx, findings); err != nil {
+			return err
+		}
+	case scanFormatSARIF:
+		if err := writeScanSARIF(os.Stdout, ctx, findings); err != nil {
+			return err
+		}
+	default:
+		printScanText(findings, explain)
+	}
+
+	if count := countMatchedFindings(findings); failOnFindings && count > 0 {
+		return fmt.Errorf("%d sensitive file(s) detected", count)
+	}
+	return nil
+}
+
+func writeScanBaseline(ctx domain.ProjectContext, roots []string, opts domain.ScanOptions) error {
+	opts.Baseline = domain.NewBaseline(ctx.ProjectPath)
+	opts.IndexPath = ""
+	detections, err := domain.ScanSensitiveFiles(roots, opts)
+	if err != nil {
+		return err
+	}
+
+	var entries []domain.BaselineEntry
+	for _, d := range detections {
+		reasons, err := domain.SensitiveFindings(d.Path, opts)
+		if err != nil {
+			return fmt.Errorf("fingerprint %s: %w", d.Path, err)
+		}
+		for _, reason := range reasons {
+			entries = append(entries, opts.Baseline.Entry(d.Path, reason))
+		}
+	}
+	if err := domain.WriteBaseline(ctx.ProjectPath, entries); err != nil {
+		return err
+	}
+	fmt.Printf("Wrote %d finding(s) in %d file(s) to %s\n", len(entries), len(detections), domain.BaselinePath(ctx.ProjectPath))
+	return nil
+}
+
+func scanGitHistory(roots []string, opts domain.ScanOptions) ([]scanFinding, error) {
+	repoRoot, err := gitcli.RepoRoot(roots[0])
+	if err != nil {
+		return nil, err
+	}
+	pathspecs, err := gitPathspecs(repoRoot, roots)
+	if err != nil {
+		return nil, err
+	}
+	blobs, err := gitcli.HistoryBlobs(repoRoot, pathspecs)
+	if err != nil {
+		return nil, err
+	}
+	return scanGitBlobs(repoRoot, blobs, opts)
+}
+
+func scanGitIndex(roots []string, opts domain.ScanOptions) ([]scanFinding, error) {
+	repoRoot, err := gitcli.RepoRoot(roots[0])
+	if err != nil {
+		return nil, err
+	}
+	pathspecs, err := gitPathspecs(repoRoot, roots)
+	if err != nil {
+		return nil, err
+	}
+	blobs, err := gitcli.StagedBlobs(repoRoot, pathspecs)
+	if err != nil {
+		return nil, err
+	}
+	return scanGitBlobs(repoRoot, blobs, opts)
+}
+
+func scanGitBlobs(repoRoot string, blobs []gitcli.Blob, opts domain.ScanOptions) ([]scanFinding, error) {
+	if len(blobs) == 0 {
+		return nil, nil
+	}
+	var ignorer *domain.PathIgnorer
+	if !opts.NoIgnore {
+		var err error
+		if ignorer, err = domain.NewPathIgnorer(repoRoot); err != nil {
+			return nil, err
+		}
+	}
+	reader, err := gitcli.NewBlobReader(repoRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []scanFinding
+	seen := make(map[string]struct{}, len(blobs))
+	for _, blob := range blobs {
+		key := blob.ObjectID + "\x00" + blob.Path
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		if inIgnoredDir(blob.Path) {
+			continue
+		}
+		if ignorer != nil {
+			ignored, err := ignorer.Ignored(blob.Path)
+			if err != nil {
+				reader.Close()
+				return nil, err
+			}
+			if ignored {
+				continue
+			}
+		}
+
+		path := filepath.Join(repoRoot, filepath.FromSlash(blob.Path))
+		var reason domain.SensitiveReason
+		err := reader.Read(blob.ObjectID, func(r io.Reader) error {
+			var err error
+			reason, err = domain.DetectSensitiveBlob(path, r, opts)
+			return err
+		})
+		if err != nil {
+			reader.Close()
+			return nil, fmt.Errorf("scan %s at %s: %w", blob.Path, shortCommit(blob.Commit.Hash), err)
+		}
+		if reason.Matched() || len(reason.Suppressed) > 0 {
+			findings = append(findings, scanFinding{Detection: domain.Detection{Path: path, Reason: reason}, Commit: blob.Commit})
+		}
+	}
+	if err := reader.Close(); err != nil {
+		return nil, fmt.Errorf("git cat-file: %w", err)
+	}
+	return findings, nil
+}
+
+func gitPathspecs(repoRoot string, roots []string) ([]string, error) {
+	var pathspecs []string
+	for _, root := range roots {
+		abs, err := filepath.Abs(root)
+		if err != nil {
+			return nil, err
+		}
+		if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+			abs = resolved
+		}
+		rel, err := filepath.Rel(repoRoot, abs)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return nil, fmt.Errorf("%s is outside git repository %s", root, repoRoot)
+		}
+		if rel == "." {
+			return nil, nil
+		}
+		pathspecs = append(pathspecs, ":(top,literal)"+filepath.ToSlash(rel))
+	}
+	return pathspecs, nil
+}
+
+func inIgnoredDir(relPath string) bool {
+	parts := strings.Split(strings.ToLower(relPath), "/")
+	for _, part := range parts[:len(parts)-1] {
+		if _, skip := domain.IgnoredDirNames[part]; skip {
+			return true
+		}
+	}
+	return false
+}
+
+func countMatchedFindings(findings []scanFinding) int {
+	count := 0
+	for _, d := range findings {
+		if d.Reason.Matched() {
+			count++
+		}
+	}
+	return count
+}
+
+func printScanText(findings []scanFinding, explain bool) {
+	count := countMatchedFindings(findings)
+	if count == 0 && !explain {
+		fmt.Println("No sensitive files detected.")
+		return
+	}
+
+	for _, d := range findings {
+		switch {
+		case explain:
+			printScanExplanation(d)
+		case d.Commit.Hash != "":
+			fmt.Printf("%s %s (%s) by %s <%s> on %s\n", shortCommit(d.Commit.Hash), d.Path, d.Reason, d.Commit.Author, d.Commit.AuthorEmail, d.Commit.Date)
+		case d.Reason.Line > 0 || d.Reason.Entry != "":
+			fmt.Printf("%s (%s)\n", d.Path, d.Reason)
+		default:
+			fmt.Println(d.Path)
+		}
+	}
+	if count == 0 {
+		fmt.Println("No sensitive files detected.")
+		return
+	}
+	fmt.Printf("Detected %d sensitive file(s).\n", count)
+}
+
+func printScanExplanation(d scanFinding) {
+	fmt.Println(d.Path)
+	if d.Commit.Hash != "" {
+		fmt.Printf("  commit: %s\n", d.Commit.Hash)
+		fmt.Printf("  author: %s <%s>\n", d.Commit.Author, d.Commit.AuthorEmail)
+		fmt.Printf("  date: %s\n", d.Commit.Date)
+	}
+	reason := d.Reason
+	if !reason.Matched() {
+		fmt.Println("  not flagged: all matches are suppressed")
+		printSuppressedMatches(reason.Suppressed)
+		return
+	}
+	switch reason.Rule {
+	case domain.RuleExactName:
+		fmt.Printf("  rule: %s (file name is %s)\n", reason.Rule, reason.Pattern)
+	case domain.RuleEnvFile, domain.RuleSuffix:
+		fmt.Printf("  rule: %s (file name matches %s)\n", reason.Rule, reason.Pattern)
+	case domain.RuleSensitiveDir:
+		fmt.Printf("  rule: %s (inside %s)\n", reason.Rule, reason.Pattern)
+	default:
+		fmt.Printf("  rule: %s (%s)\n", reason.Rule, reason.Pattern)
+	}
+	fmt.Printf("  severity: %s\n", reason.Severity)
+	if reason.Source {
+		fmt.Println("  source file: not locked unless SECRETVAULT_LOCK_SOURCE=1")
+	}
+	if reason.Entry != "" {
+		fmt.Printf("  archive entry: %s\n", reason.Entry)
+	}
+	if reason.Line > 0 {
+		fmt.Printf("  line: %d\n", reason.Line)
+	}
+	if reason.Excerpt != "" {
+		fmt.Printf("  excerpt: %s\n", reason.Excerpt)
+	}
+	printSuppressedMatches(reason.Suppressed)
+}
+
+func printSuppressedMatches(matches []domain.SensitiveReason) {
+	if len(matches) == 0 {
+		return
+	}
+	fmt.Println("  suppressed:")
+	for _, m := range matches {
+		fmt.Printf("    line %d: %s (%s)", m.Line, m.Rule, m.Severity)
+		if m.Excerpt != "" {
+			fmt.Printf(" %s", m.Excerpt)
+		}
+		fmt.Println()
+	}
+}
@@ -0,0 +1,102 @@
+package application
+
+import (
+	"flag"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"secrets-vault/internal/domain"
+	"secrets-vault/internal/integrations/opcli"
+)
+
+func RunSetCommand(args []string, cliName string) error {
+	flags := flag.NewFlagSet("set", flag.ContinueOnError)
+	var file string
+	var absorb bool
+	flags.StringVar(&file, "file", defaultDotenvFile, "tracked dotenv file to update")
+	flags.BoolVar(&absorb, "absorb", false, "also update the 1Password document when the file was absorbed")
+	assignments, err := parseInterspersed(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(assignments) == 0 {
+		return fmt.Errorf("usage: %s set KEY=VALUE ... [--file %s] [--absorb]", cliName, defaultDotenvFile)
+	}
+
+	ctx, err := domain.LoadProjectContext()
+	if err != nil {
+		return err
+	}
+	entry, target, err := trackedEntry(ctx, file, cliName)
+	if err != nil {
+		return err
+	}
+	plaintext, err := resolvePlaintext(ctx, entry, target, cliName)
+	if err != nil {
+		return err
+	}
+
+	updated := plaintext
+	for _, assignment := range assignments {
+		name, value, ok := strings.Cut(assignment, "=")
+		if !ok || !domain.ValidDotenvKey(name) {
+			return fmt.Errorf("invalid assignment %q (expected KEY=VALUE)", assignment)
+		}
+		dotenv, err := domain.ParseDotenv(updated)
+		if err != nil {
+			return fmt.Errorf("parse %s: %w", file, err)
+		}
+		updated = dotenv.Set(name, value)
+	}
+
+	if domain.FileExists(target) && !domain.IsValueLockMode(entry.LockMode) {
+		if err := domain.WriteAtomic(target, updated, fileModeOrDefault(entry.OriginalMode)); err != nil {
+			return err
+		}
+		fmt.Printf("updated %s (unlocked; run lock to re-encrypt)\n", target)
+	} else {
+		key, err := loadProjectKey(ctx, cliName)
+		if err != nil {
+			return err
+		}
+		if err := saveLockedPlaintext(ctx, entry, target, updated, key); err != nil {
+			return err
+		}
+		fmt.Printf("updated %s\n", target)
+	}
+
+	if !absorb {
+		warnStaleOnePassword(entry)
+		return nil
+	}
+	if strings.TrimSpace(entry.OnePasswordDocument) == "" {
+		fmt.Printf("%s was never absorbed; skipping 1Password update\n", target)
+		return nil
+	}
+	if err := reabsorbPlaintext(ctx, entry, target, updated); err != nil {
+		return fmt.Errorf("update 1Password document for %s: %w", target, err)
+	}
+	fmt.Printf("updated 1Password document %s\n", entry.OnePasswordDocument)
+	return nil
+}
+
+func reabsorbPlaintext(ctx domain.ProjectContext, entry domain.VaultEntry, target string, plaintext []byte) error {
+	dir, err := domain.PrivateTempDir("secretvault-absorb-")
+	if err != nil {
+		return err
+	}
+	defer domain.ShredDir(dir)
+	upload := filepath.Join(dir, filepath.Base(target))
+	if err := domain.WriteAtomic(upload, plaintext, 0o600); err != nil {
+		return err
+	}
+	if err := opcli.ReplaceDocument(entry, upload); err != nil {
+		return err
+	}
+	checksum, err := opcli.FileSHA256(upload)
+	if err != nil {
+		return err
+	}
+	return opcli.AnnotateVaultEntry(ctx, target, entry.OnePasswordVault, entry.OnePasswordDocument, entry.OnePasswordTitle, checksum)
+}
@@ -0,0 +1,93 @@
+package application
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"secrets-vault/internal/domain"
+	"secrets-vault/internal/integrations/keyringstore"
+)
+
+func TestRunSetCommandUpdatesEveryLockMode(t *testing.T) {
+	t.Setenv("SECRETVAULT_HOME", t.TempDir())
+	t.Setenv("SECRETVAULT_KEYRING_FALLBACK", "file")
+	dir := t.TempDir()
+	chdir(t, dir)
+	ctx, err := domain.LoadProjectContext()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := bytes.Repeat([]byte{4}, 32)
+	if err := keyringstore.SaveProjectKey(ctx, key); err != nil {
+		t.Fatal(err)
+	}
+
+	original := "# db\nDB_USER=app\nexport DB_PASSWORD=\"old\"\n"
+	fileTarget := filepath.Join(ctx.ProjectPath, ".env")
+	payload, err := domain.EncryptPayload([]byte(original), key, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := domain.WriteAtomic(fileTarget+domain.EncryptedExt, payload, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := domain.UpsertVaultEntry(ctx, fileTarget, fileTarget+domain.EncryptedExt, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	valueTarget := filepath.Join(ctx.ProjectPath, ".env.production")
+	lock := domain.ValueLock{Mode: domain.LockModeDotenvValues}
+	locked, _, err := domain.LockValues(lock, valueTarget, []byte(original), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := domain.WriteAtomic(valueTarget, locked, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := domain.UpsertValueLockedVaultEntry(ctx, valueTarget, []byte(original), key, 0o600, lock); err != nil {
+		t.Fatal(err)
+	}
+
+	manifest, manifestPath, err := domain.LoadVaultManifest(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	absorbed := manifest.Entries[valueTarget]
+	absorbed.OnePasswordDocument = "doc-production"
+	absorbed.ChecksumSHA256 = "checksum-of-doc"
+	manifest.Entries[valueTarget] = absorbed
+	if err := domain.SaveVaultManifest(manifestPath, manifest); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "# db\nDB_USER=app\nexport DB_PASSWORD=\"n3w\"\nAPI_TOKEN=tok\n"
+	for _, file := range []string{".env", ".env.production"} {
+		out := captureStdout(t, func() error {
+			return RunSetCommand([]string{"DB_PASSWORD=n3w", "API_TOKEN=tok", "--file", file}, "secretvault")
+		})
+		target := filepath.Join(ctx.ProjectPath, file)
+		entry, _, err := trackedEntry(ctx, target, "secretvault")
+		if err != nil {
+			t.Fatal(err)
+		}
+		plaintext, err := lockedPlaintext(ctx, entry, target, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(plaintext) != want {
+			t.Fatalf("%s: got\n%s\nwant\n%s", file, plaintext, want)
+		}
+		if file == ".env.production" && (entry.OnePasswordDocument == "" || entry.ChecksumSHA256 != "checksum-of-doc" || !strings.Contains(out, "still holds the previous version")) {
+			t.Fatalf("%s: expected the stale 1Password document to stay flagged, got %+v and %q", file, entry, out)
+		}
+	}
+
+	if domain.FileExists(fileTarget) {
+		t.Fatal("set must not write plaintext for a file-locked entry")
+	}
+	if err := RunSetCommand([]string{"not a key=1"}, "secretvault"); err == nil {
+		t.Fatal("expected an invalid key to be rejected")
+	}
+}
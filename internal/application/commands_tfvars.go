@@ -0,0 +1,283 @@
+package application
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/zalando/go-keyring"
+
+	"secrets-vault/internal/domain"
+	"secrets-vault/internal/integrations/keyringstore"
+)
+
+const runtimeTfvarsSuffix = ".secretvault.auto.tfvars"
+
+func RunTfvarsCommand(args []string, cliName string) error {
+	if len(args) == 0 || args[0] != "decrypt" {
+		return fmt.Errorf("usage: %s tfvars decrypt [--out <dir>] [path ...]", cliName)
+	}
+	flags := flag.NewFlagSet("tfvars decrypt", flag.ContinueOnError)
+	var outDir string
+	flags.StringVar(&outDir, "out", "", "directory for the decrypted .tfvars files (default: a new temp directory)")
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	ctx, err := domain.LoadProjectContext()
+	if err != nil {
+		return err
+	}
+	entries, err := tfvarsLockedEntries(ctx, domain.NormalizeRoots(flags.Args()))
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return errors.New("no value-locked .tfvars files found")
+	}
+	key, err := loadProjectKey(ctx, cliName)
+	if err != nil {
+		return err
+	}
+
+	if outDir == "" {
+		if outDir, err = domain.PrivateTempDir("secretvault-tfvars-"); err != nil {
+			return err
+		}
+	} else if err := os.MkdirAll(outDir, 0o700); err != nil {
+		return err
+	}
+	written, err := writeDecryptedTfvars(ctx, key, entries, outDir)
+	if err != nil {
+		return err
+	}
+	for _, path := range written {
+		fmt.Println(path)
+	}
+	return nil
+}
+
+func tfvarsLockedEntries(ctx domain.ProjectContext, roots []string) ([]domain.VaultEntry, error) {
+	entries, err := valueLockedTargets(ctx, roots)
+	if err != nil {
+		return nil, err
+	}
+	out := entries[:0]
+	for _, entry := range entries {
+		if entry.LockMode == domain.LockModeTfvarsValues {
+			out = append(out, entry)
+		}
+	}
+	return out, nil
+}
+
+func writeDecryptedTfvars(ctx domain.ProjectContext, key []byte, entries []domain.VaultEntry, outDir string) ([]string, error) {
+	var written []string
+	used := map[string]bool{}
+	for _, entry := range entries {
+		target, plaintext, err := decryptTfvarsEntry(ctx, key, entry)
+		if err != nil {
+			return written, err
+		}
+		name := filepath.Base(target)
+		if used[name] {
+			name = tfvarsStem(target) + "-" + entry.FileID[:8] + strings.TrimPrefix(name, tfvarsStem(target))
+		}
+		used[name] = true
+
+		dst := filepath.Join(outDir, name)
+		if err := domain.WriteAtomic(dst, plaintext, 0o600); err != nil {
+			return written, err
+		}
+		written = append(written, dst)
+	}
+	return written, nil
+}
+
+func decryptTfvarsEntry(ctx domain.ProjectContext, key []byte, entry domain.VaultEntry) (string, []byte, error) {
+	target := domain.ResolveEntryTargetPath(ctx, entry)
+	data, err := os.ReadFile(target)
+	if err != nil {
+		return target, nil, err
+	}
+	plaintext, _, err := domain.DecryptTfvarsValues(data, key)
+	if err != nil {
+		return target, nil, fmt.Errorf("decrypt %s: %w", target, err)
+	}
+	return target, plaintext, nil
+}
+
+func tfvarsStem(path string) string {
+	return strings.TrimSuffix(strings.TrimSuffix(filepath.Base(path), ".tfvars"), ".auto")
+}
+
+// runtimeTfvarsLink names the link that makes Terraform auto-load the
+// decrypted copy of target right after target itself: terraform.tfvars is read
+// before every *.auto.tfvars and those in lexical order. Files Terraform does
+// not auto-load get no link; they only reach it through -var-file.
+func runtimeTfvarsLink(target string) string {
+	base := filepath.Base(target)
+	switch {
+	case base == "terraform.tfvars":
+		return filepath.Join(filepath.Dir(target), "0-"+base+runtimeTfvarsSuffix)
+	case strings.HasSuffix(base, ".auto.tfvars"):
+		return filepath.Join(filepath.Dir(target), base+runtimeTfvarsSuffix)
+	}
+	return ""
+}
+
+// rewriteVarFileArgs points every -var-file flag that names a value-locked
+// file at its decrypted copy, resolving relative paths against -chdir.
+func rewriteVarFileArgs(args []string, decrypted map[string]string) []string {
+	base := ""
+	for _, arg := range args {
+		if dir, ok := strings.CutPrefix(arg, "-chdir="); ok {
+			base = dir
+		}
+	}
+	resolve := func(path string) (string, bool) {
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(base, path)
+		}
+		copyPath, ok := decrypted[canonicalPath(path)]
+		return copyPath, ok
+	}
+
+	out := append([]string(nil), args...)
+	for i := 0; i < len(out); i++ {
+		if !strings.HasPrefix(out[i], "-") {
+			continue
+		}
+		name, value, hasValue := strings.Cut(strings.TrimLeft(out[i], "-"), "=")
+		if name != "var-file" {
+			continue
+		}
+		if !hasValue {
+			if i+1 < len(out) {
+				if copyPath, ok := resolve(out[i+1]); ok {
+					out[i+1] = copyPath
+				}
+			}
+			i++
+			continue
+		}
+		if copyPath, ok := resolve(value); ok {
+			out[i] = "-var-file=" + copyPath
+		}
+	}
+	return out
+}
+
+func canonicalPath(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return filepath.Clean(path)
+	}
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		return resolved
+	}
+	return abs
+}
+
+func prepareRuntimeTfvars(cliName string, commandArgs []string) ([]string, func(), error) {
+	noop := func() {}
+	ctx, err := domain.LoadProjectContext()
+	if err != nil {
+		return commandArgs, noop, err
+	}
+	entries, err := tfvarsLockedEntries(ctx, []string{ctx.ProjectPath})
+	if err != nil || len(entries) == 0 {
+		return commandArgs, noop, err
+	}
+	key, err := loadProjectKey(ctx, cliName)
+	if err != nil {
+		return commandArgs, noop, err
+	}
+
+	dir, err := domain.PrivateTempDir("secretvault-tfvars-")
+	if err != nil {
+		return commandArgs, noop, err
+	}
+	var (
+		mu    sync.Mutex
+		links []string
+		done  bool
+	)
+	signals := make(chan os.Signal, 1)
+	stopped := make(chan struct{})
+	cleanup := func() {
+		mu.Lock()
+		defer mu.Unlock()
+		if done {
+			return
+		}
+		done = true
+		signal.Stop(signals)
+		close(stopped)
+		for _, link := range links {
+			_ = os.Remove(link)
+		}
+		_ = domain.ShredDir(dir)
+	}
+	// An interrupted run must not leave decrypted values behind, so clean up
+	// first and then let the signal terminate the process as it normally would.
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case sig := <-signals:
+			cleanup()
+			if p, err := os.FindProcess(os.Getpid()); err == nil {
+				_ = p.Signal(sig)
+			}
+		case <-stopped:
+		}
+	}()
+
+	decryptedCopies := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		target, plaintext, err := decryptTfvarsEntry(ctx, key, entry)
+		if err != nil {
+			cleanup()
+			return commandArgs, noop, err
+		}
+		decrypted := filepath.Join(dir, entry.FileID+".tfvars")
+		if err := domain.WriteAtomic(decrypted, plaintext, 0o600); err != nil {
+			cleanup()
+			return commandArgs, noop, err
+		}
+		decryptedCopies[canonicalPath(target)] = decrypted
+
+		link := runtimeTfvarsLink(target)
+		if link == "" {
+			continue
+		}
+		if info, err := os.Lstat(link); err == nil && info.Mode()&os.ModeSymlink != 0 {
+			_ = os.Remove(link)
+		}
+		if err := os.Symlink(decrypted, link); err != nil {
+			cleanup()
+			return commandArgs, noop, err
+		}
+		mu.Lock()
+		links = append(links, link)
+		mu.Unlock()
+	}
+	return rewriteVarFileArgs(commandArgs, decryptedCopies), cleanup, nil
+}
+
+func loadProjectKey(ctx domain.ProjectContext, cliName string) ([]byte, error) {
+	key, err := keyringstore.LoadProjectKey(ctx)
+	if err != nil {
+		if errors.Is(err, keyring.ErrNotFound) {
+			return nil, fmt.Errorf("missing key for this project. run: %s key set", cliName)
+		}
+		return nil, err
+	}
+	return key, nil
+}
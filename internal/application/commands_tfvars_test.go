@@ -0,0 +1,149 @@
+package application
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"testing"
+
+	"secrets-vault/internal/domain"
+	"secrets-vault/internal/integrations/keyringstore"
+)
+
+func tfvarsFixture(t *testing.T, files map[string]string) domain.ProjectContext {
+	t.Helper()
+	t.Setenv("SECRETVAULT_HOME", t.TempDir())
+	t.Setenv("SECRETVAULT_KEYRING_FALLBACK", "file")
+	dir := t.TempDir()
+	chdir(t, dir)
+	ctx, err := domain.LoadProjectContext()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := bytes.Repeat([]byte{3}, 32)
+	if err := keyringstore.SaveProjectKey(ctx, key); err != nil {
+		t.Fatal(err)
+	}
+	lock := domain.ValueLock{Mode: domain.LockModeTfvarsValues, Variables: []string{"db_password"}}
+	for rel, plaintext := range files {
+		target := filepath.Join(ctx.ProjectPath, rel)
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		locked, _, err := domain.LockValues(lock, target, []byte(plaintext), key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := domain.WriteAtomic(target, locked, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := domain.UpsertValueLockedVaultEntry(ctx, target, []byte(plaintext), key, 0o600, lock); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return ctx
+}
+
+func TestRunTfvarsDecryptKeepsSourceNames(t *testing.T) {
+	files := map[string]string{
+		"terraform.tfvars":         "db_password = \"root-secret\"\n",
+		"prod.tfvars":              "db_password = \"prod-secret\"\n",
+		"modules/db/prod.tfvars":   "db_password = \"module-secret\"\n",
+		"modules/db/x.auto.tfvars": "db_password = \"auto-secret\"\n",
+	}
+	tfvarsFixture(t, files)
+	out := t.TempDir()
+
+	printed := captureStdout(t, func() error { return RunTfvarsCommand([]string{"decrypt", "--out", out}, "secretvault") })
+	paths := strings.Fields(printed)
+	if len(paths) != len(files) {
+		t.Fatalf("expected one path per file, got %q", printed)
+	}
+	var names, contents []string
+	for _, path := range paths {
+		if filepath.Dir(path) != out {
+			t.Fatalf("%s was not written to --out", path)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		name := filepath.Base(path)
+		if strings.HasPrefix(name, "prod-") {
+			name = "prod-<id>.tfvars"
+		}
+		names = append(names, name)
+		contents = append(contents, string(data))
+	}
+	sort.Strings(names)
+	sort.Strings(contents)
+	if strings.Join(names, " ") != "prod-<id>.tfvars prod.tfvars terraform.tfvars x.auto.tfvars" {
+		t.Fatalf("expected source names to be kept, got %v", names)
+	}
+	want := []string{files["modules/db/x.auto.tfvars"], files["modules/db/prod.tfvars"], files["prod.tfvars"], files["terraform.tfvars"]}
+	sort.Strings(want)
+	if strings.Join(contents, "") != strings.Join(want, "") {
+		t.Fatalf("unexpected decrypted contents: %q", contents)
+	}
+
+	if err := RunTfvarsCommand([]string{"decrypt", "--out", out, "missing.tfvars"}, "secretvault"); err == nil {
+		t.Fatal("expected an error when no value-locked file matches")
+	}
+}
+
+func TestRunRunCommandDecryptsTfvarsForTerraform(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("wrapped command is a shell script")
+	}
+	ctx := tfvarsFixture(t, map[string]string{
+		"terraform.tfvars": "db_password = \"root-secret\"\n",
+		"prod.tfvars":      "db_password = \"prod-secret\"\n",
+	})
+	seen := filepath.Join(t.TempDir(), "seen")
+	t.Setenv("SEEN", seen)
+
+	script := `ls > "$SEEN.ls"; for f in *.secretvault.auto.tfvars "${1#-var-file=}" "$3"; do cat "$f"; done > "$SEEN"`
+	err := RunRunCommand([]string{"--", "sh", "-c", script, "sh", "-var-file=prod.tfvars", "-var-file", "prod.tfvars"}, "secretvault")
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	got, err := os.ReadFile(seen)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "db_password = \"root-secret\"\ndb_password = \"prod-secret\"\ndb_password = \"prod-secret\"\n"
+	if string(got) != want {
+		t.Fatalf("wrapped command saw:\n%s\nwant:\n%s", got, want)
+	}
+	listing, err := os.ReadFile(seen + ".ls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	links := strings.Fields(string(listing))
+	if strings.Join(links, " ") != "0-terraform.tfvars.secretvault.auto.tfvars prod.tfvars terraform.tfvars" {
+		t.Fatalf("expected only terraform.tfvars to be linked for auto-loading, got %v", links)
+	}
+
+	entries, err := os.ReadDir(ctx.ProjectPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.Name(), runtimeTfvarsSuffix) {
+			t.Fatalf("runtime link %s survived the run", entry.Name())
+		}
+	}
+	for _, name := range []string{"terraform.tfvars", "prod.tfvars"} {
+		data, err := os.ReadFile(filepath.Join(ctx.ProjectPath, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bytes.Contains(data, []byte("secret\"")) {
+			t.Fatalf("%s holds plaintext after the run: %s", name, data)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package application
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"secrets-vault/internal/domain"
+)
+
+func RunValidateCommand(args []string, cliName string) error {
+	flags := flag.NewFlagSet("validate", flag.ContinueOnError)
+	var schemaPath string
+	flags.StringVar(&schemaPath, "schema", "", "schema file (default: .env.schema in the project root)")
+	paths, err := parseInterspersed(flags, args)
+	if err != nil {
+		return err
+	}
+
+	ctx, err := domain.LoadProjectContext()
+	if err != nil {
+		return err
+	}
+	return validateTrackedDotenv(ctx, schemaPath, paths, cliName, true)
+}
+
+func runValidateRequested() bool {
+	v, err := strconv.ParseBool(strings.TrimSpace(os.Getenv("SECRETVAULT_RUN_VALIDATE")))
+	return err == nil && v
+}
+
+func validateTrackedDotenv(ctx domain.ProjectContext, schemaPath string, paths []string, cliName string, verbose bool) error {
+	if schemaPath == "" {
+		schemaPath = filepath.Join(ctx.ProjectPath, domain.DotenvSchemaFile)
+	}
+	data, err := os.ReadFile(schemaPath)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("%s not found; declare the expected variables there first", schemaPath)
+	}
+	if err != nil {
+		return err
+	}
+	schema, err := domain.ParseDotenvSchema(data)
+	if err != nil {
+		return fmt.Errorf("parse %s: %w", schemaPath, err)
+	}
+
+	var sources []domain.DotenvExampleSource
+	if len(paths) == 0 {
+		if sources, err = trackedDotenvSources(ctx, schemaPath, cliName); err != nil {
+			return err
+		}
+	}
+	for _, path := range paths {
+		entry, target, err := trackedEntry(ctx, path, cliName)
+		if err != nil {
+			return err
+		}
+		source, err := dotenvSource(ctx, entry, target, cliName)
+		if err != nil {
+			return err
+		}
+		sources = append(sources, source)
+	}
+	if len(sources) == 0 {
+		return fmt.Errorf("no tracked dotenv files. run: %s lock .env", cliName)
+	}
+
+	total, failed := 0, 0
+	for _, source := range sources {
+		problems := schema.Validate(source.File)
+		if len(problems) == 0 {
+			if verbose {
+				fmt.Printf("%s: ok\n", source.Path)
+			}
+			continue
+		}
+		total += len(problems)
+		failed++
+		fmt.Printf("%s: %d problem(s)\n", source.Path, len(problems))
+		for _, p := range problems {
+			line := fmt.Sprintf("  %-9s %s", p.Kind, p.Key)
+			if p.Line > 0 {
+				line += fmt.Sprintf(" (line %d)", p.Line)
+			}
+			if p.Detail != "" {
+				line += ": " + p.Detail
+			}
+			fmt.Println(line)
+		}
+	}
+	if total > 0 {
+		return fmt.Errorf("validation failed: %d problem(s) in %d of %d dotenv file(s)", total, failed, len(sources))
+	}
+	return nil
+}
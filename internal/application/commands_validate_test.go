@@ -0,0 +1,74 @@
+package application
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"secrets-vault/internal/domain"
+	"secrets-vault/internal/integrations/keyringstore"
+)
+
+func TestRunValidateCommandFailsOnSchemaViolations(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("wrapped command is a shell script")
+	}
+	t.Setenv("SECRETVAULT_HOME", t.TempDir())
+	t.Setenv("SECRETVAULT_KEYRING_FALLBACK", "file")
+	dir := t.TempDir()
+	chdir(t, dir)
+	ctx, err := domain.LoadProjectContext()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := bytes.Repeat([]byte{8}, 32)
+	if err := keyringstore.SaveProjectKey(ctx, key); err != nil {
+		t.Fatal(err)
+	}
+
+	schema := "DB_PASSWORD=required\nSECRET_TOKEN=required,pattern:tok_[a-z]{8}\nPORT=int\n"
+	if err := os.WriteFile(filepath.Join(ctx.ProjectPath, domain.DotenvSchemaFile), []byte(schema), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	writeDotenv := func(plaintext string) {
+		t.Helper()
+		target := filepath.Join(ctx.ProjectPath, ".env")
+		lock := domain.ValueLock{Mode: domain.LockModeDotenvValues}
+		locked, _, err := domain.LockValues(lock, target, []byte(plaintext), key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := domain.WriteAtomic(target, locked, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := domain.UpsertValueLockedVaultEntry(ctx, target, []byte(plaintext), key, 0o600, lock); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	writeDotenv("DB_PASSWORD=hunter2hunter2\nSECRET_TOKEN=tok_abcdefgh\nPORT=5432\n")
+	out := captureStdout(t, func() error { return RunValidateCommand(nil, "secretvault") })
+	if !strings.Contains(out, ".env: ok") {
+		t.Fatalf("expected a passing validation, got %q", out)
+	}
+
+	writeDotenv("SECRET_TOKEN=tok_WRONG-leaked\nPORT=eighty\n")
+	if err := RunValidateCommand(nil, "secretvault"); err == nil {
+		t.Fatal("expected validate to fail on a missing key and bad values")
+	}
+	marker := filepath.Join(t.TempDir(), "started")
+	if err := RunRunCommand([]string{"--validate", "--", "touch", marker}, "secretvault"); err == nil || !strings.Contains(err.Error(), "not starting touch") {
+		t.Fatalf("expected run --validate to refuse to start, got %v", err)
+	}
+	if domain.FileExists(marker) {
+		t.Fatal("run --validate started the command despite a schema violation")
+	}
+
+	detections, err := domain.ScanSensitiveFiles([]string{filepath.Join(ctx.ProjectPath, domain.DotenvSchemaFile)}, domain.DefaultScanOptions())
+	if err != nil || len(detections) != 0 {
+		t.Fatalf("expected schema key names not to be flagged, got %+v, %v", detections, err)
+	}
+}
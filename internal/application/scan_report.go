@@ -0,0 +1,322 @@
+package application
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"secrets-vault/internal/domain"
+	"secrets-vault/internal/integrations/gitcli"
+)
+
+const (
+	scanFormatText  = "text"
+	scanFormatJSON  = "json"
+	scanFormatSARIF = "sarif"
+
+	sarifSchemaURI = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion   = "2.1.0"
+	sarifRootBase  = "%SRCROOT%"
+	toolInfoURI    = "https://github.com/jhartzell/secretvault"
+)
+
+type scanJSONReport struct {
+	Version     int              `json:"version"`
+	ProjectPath string           `json:"project_path"`
+	Findings    []scanJSONResult `json:"findings"`
+}
+
+type scanJSONResult struct {
+	Path         string `json:"path"`
+	RelativePath string `json:"relative_path,omitempty"`
+	RuleID       string `json:"rule_id"`
+	Description  string `json:"description"`
+	Severity     string `json:"severity"`
+	Line         int    `json:"line,omitempty"`
+	Entry        string `json:"archive_entry,omitempty"`
+	Source       bool   `json:"source_file,omitempty"`
+	Excerpt      string `json:"excerpt,omitempty"`
+	Fingerprint  string `json:"fingerprint,omitempty"`
+	Commit       string `json:"commit,omitempty"`
+	Author       string `json:"author,omitempty"`
+	AuthorEmail  string `json:"author_email,omitempty"`
+	CommittedAt  string `json:"committed_at,omitempty"`
+}
+
+type scanFinding struct {
+	domain.Detection
+	Commit gitcli.Commit
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool               sarifTool                   `json:"tool"`
+	OriginalURIBaseIDs map[string]sarifArtifactLoc `json:"originalUriBaseIds,omitempty"`
+	Results            []sarifResult               `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string            `json:"id"`
+	Name                 string            `json:"name"`
+	ShortDescription     sarifMessage      `json:"shortDescription"`
+	DefaultConfiguration sarifRuleConfig   `json:"defaultConfiguration"`
+	Properties           sarifRuleMetadata `json:"properties"`
+}
+
+type sarifRuleConfig struct {
+	Level string `json:"level"`
+}
+
+type sarifRuleMetadata struct {
+	Tags             []string `json:"tags"`
+	SecuritySeverity string   `json:"security-severity"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID              string            `json:"ruleId"`
+	RuleIndex           int               `json:"ruleIndex"`
+	Level               string            `json:"level"`
+	Message             sarifMessage      `json:"message"`
+	Locations           []sarifLocation   `json:"locations"`
+	PartialFingerprints map[string]string `json:"partialFingerprints,omitempty"`
+	Properties          map[string]string `json:"properties,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLoc `json:"artifactLocation"`
+	Region           *sarifRegion     `json:"region,omitempty"`
+}
+
+type sarifArtifactLoc struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+func findingsFromDetections(detections []domain.Detection) []scanFinding {
+	out := make([]scanFinding, 0, len(detections))
+	for _, d := range detections {
+		out = append(out, scanFinding{Detection: d})
+	}
+	return out
+}
+
+func writeScanJSON(w io.Writer, ctx domain.ProjectContext, findings []scanFinding) error {
+	return writeIndentedJSON(w, buildScanJSONReport(ctx, findings))
+}
+
+func writeScanSARIF(w io.Writer, ctx domain.ProjectContext, findings []scanFinding) error {
+	return writeIndentedJSON(w, buildSARIFLog(ctx, findings))
+}
+
+func writeIndentedJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func buildScanJSONReport(ctx domain.ProjectContext, findings []scanFinding) scanJSONReport {
+	report := scanJSONReport{Version: 1, ProjectPath: ctx.ProjectPath, Findings: make([]scanJSONResult, 0, len(findings))}
+	for _, d := range findings {
+		rel, _ := domain.ProjectRelativePath(ctx.ProjectPath, d.Path)
+		report.Findings = append(report.Findings, scanJSONResult{
+			Path:         d.Path,
+			RelativePath: filepath.ToSlash(rel),
+			RuleID:       d.Reason.Rule,
+			Description:  scanRuleDescription(d.Reason),
+			Severity:     d.Reason.Severity,
+			Line:         d.Reason.Line,
+			Entry:        d.Reason.Entry,
+			Source:       d.Reason.Source,
+			Excerpt:      d.Reason.Excerpt,
+			Fingerprint:  d.Reason.Fingerprint,
+			Commit:       d.Commit.Hash,
+			Author:       d.Commit.Author,
+			AuthorEmail:  d.Commit.AuthorEmail,
+			CommittedAt:  d.Commit.Date,
+		})
+	}
+	return report
+}
+
+func buildSARIFLog(ctx domain.ProjectContext, findings []scanFinding) sarifLog {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "secretvault",
+			InformationURI: toolInfoURI,
+			Rules:          []sarifRule{},
+		}},
+		OriginalURIBaseIDs: map[string]sarifArtifactLoc{
+			sarifRootBase: {URI: fileURI(ctx.ProjectPath, true)},
+		},
+		Results: make([]sarifResult, 0, len(findings)),
+	}
+
+	ruleIndex := map[string]int{}
+	for _, d := range findings {
+		reason := d.Reason
+		idx, ok := ruleIndex[reason.Rule]
+		if !ok {
+			idx = len(run.Tool.Driver.Rules)
+			ruleIndex[reason.Rule] = idx
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{
+				ID:                   reason.Rule,
+				Name:                 sarifRuleName(reason.Rule),
+				ShortDescription:     sarifMessage{Text: scanRuleDescription(reason)},
+				DefaultConfiguration: sarifRuleConfig{Level: sarifLevel(reason.Severity)},
+				Properties: sarifRuleMetadata{
+					Tags:             []string{"security", "secrets"},
+					SecuritySeverity: sarifSecuritySeverity(reason.Severity),
+				},
+			})
+		}
+
+		location := sarifPhysicalLocation{ArtifactLocation: sarifArtifactLoc{URI: fileURI(d.Path, false)}}
+		if rel, ok := domain.ProjectRelativePath(ctx.ProjectPath, d.Path); ok {
+			location.ArtifactLocation = sarifArtifactLoc{URI: escapeURIPath(filepath.ToSlash(rel)), URIBaseID: sarifRootBase}
+		}
+		if reason.Line > 0 && reason.Entry == "" {
+			location.Region = &sarifRegion{StartLine: reason.Line}
+		}
+
+		result := sarifResult{
+			RuleID:    reason.Rule,
+			RuleIndex: idx,
+			Level:     sarifLevel(reason.Severity),
+			Message:   sarifMessage{Text: scanResultMessage(reason)},
+			Locations: []sarifLocation{{PhysicalLocation: location}},
+		}
+		if reason.Fingerprint != "" {
+			result.PartialFingerprints = map[string]string{"secretvaultFingerprint/v1": reason.Fingerprint}
+		}
+		if d.Commit.Hash != "" {
+			result.Message.Text += fmt.Sprintf(" (commit %s by %s)", shortCommit(d.Commit.Hash), d.Commit.Author)
+			result.Properties = map[string]string{
+				"commit":      d.Commit.Hash,
+				"author":      d.Commit.Author,
+				"authorEmail": d.Commit.AuthorEmail,
+				"committedAt": d.Commit.Date,
+			}
+		}
+		run.Results = append(run.Results, result)
+	}
+
+	return sarifLog{Schema: sarifSchemaURI, Version: sarifVersion, Runs: []sarifRun{run}}
+}
+
+func scanRuleDescription(reason domain.SensitiveReason) string {
+	switch reason.Rule {
+	case domain.RuleExactName:
+		return "known secret file name"
+	case domain.RuleEnvFile:
+		return "dotenv file"
+	case domain.RuleSuffix:
+		return "sensitive file extension"
+	case domain.RuleSensitiveDir:
+		return "file inside a sensitive directory"
+	default:
+		return reason.Pattern
+	}
+}
+
+func scanResultMessage(reason domain.SensitiveReason) string {
+	var msg string
+	switch reason.Rule {
+	case domain.RuleExactName, domain.RuleEnvFile, domain.RuleSuffix:
+		msg = fmt.Sprintf("Sensitive file: name matches %s", reason.Pattern)
+	case domain.RuleSensitiveDir:
+		msg = fmt.Sprintf("Sensitive file: located inside %s", reason.Pattern)
+	default:
+		msg = fmt.Sprintf("Possible secret: %s", reason.Pattern)
+	}
+	if reason.Entry != "" {
+		msg += fmt.Sprintf(" (archive entry %s)", reason.Entry)
+	}
+	return msg
+}
+
+func sarifRuleName(ruleID string) string {
+	parts := strings.Split(ruleID, "-")
+	for i, part := range parts {
+		if part == "" {
+			continue
+		}
+		parts[i] = strings.ToUpper(part[:1]) + part[1:]
+	}
+	return strings.Join(parts, "")
+}
+
+func sarifLevel(severity string) string {
+	switch severity {
+	case domain.SeverityCritical, domain.SeverityHigh:
+		return "error"
+	case domain.SeverityMedium:
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+func sarifSecuritySeverity(severity string) string {
+	switch severity {
+	case domain.SeverityCritical:
+		return "9.5"
+	case domain.SeverityHigh:
+		return "8.0"
+	case domain.SeverityMedium:
+		return "5.0"
+	default:
+		return "2.0"
+	}
+}
+
+func fileURI(path string, dir bool) string {
+	slashed := filepath.ToSlash(path)
+	if !strings.HasPrefix(slashed, "/") {
+		slashed = "/" + slashed
+	}
+	if dir && !strings.HasSuffix(slashed, "/") {
+		slashed += "/"
+	}
+	return (&url.URL{Scheme: "file", Path: slashed}).String()
+}
+
+func escapeURIPath(rel string) string {
+	return (&url.URL{Path: rel}).EscapedPath()
+}
+
+func shortCommit(hash string) string {
+	if len(hash) > 12 {
+		return hash[:12]
+	}
+	return hash
+}
@@ -0,0 +1,64 @@
+package application
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"secrets-vault/internal/domain"
+)
+
+func TestBuildSARIFLog(t *testing.T) {
+	ctx := domain.ProjectContext{ProjectPath: "/work/app"}
+	detections := []domain.Detection{
+		{Path: "/work/app/.env", Reason: domain.SensitiveReason{Rule: domain.RuleExactName, Pattern: ".env", Severity: domain.SeverityHigh}},
+		{Path: "/work/app/config/app.yaml", Reason: domain.SensitiveReason{Rule: "github-token", Pattern: "GitHub personal access or app token", Severity: domain.SeverityCritical, Line: 7}},
+		{Path: "/work/app/other.yaml", Reason: domain.SensitiveReason{Rule: "github-token", Pattern: "GitHub personal access or app token", Severity: domain.SeverityCritical, Line: 2}},
+	}
+
+	log := buildSARIFLog(ctx, findingsFromDetections(detections))
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("unexpected log header: %+v", log)
+	}
+	run := log.Runs[0]
+	if len(run.Tool.Driver.Rules) != 2 {
+		t.Fatalf("expected 2 distinct rules, got %d", len(run.Tool.Driver.Rules))
+	}
+	if len(run.Results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(run.Results))
+	}
+
+	second := run.Results[1]
+	if second.RuleID != "github-token" || second.RuleIndex != 1 || second.Level != "error" {
+		t.Fatalf("unexpected result: %+v", second)
+	}
+	loc := second.Locations[0].PhysicalLocation
+	if loc.ArtifactLocation.URI != "config/app.yaml" || loc.ArtifactLocation.URIBaseID != sarifRootBase {
+		t.Fatalf("unexpected artifact location: %+v", loc.ArtifactLocation)
+	}
+	if loc.Region == nil || loc.Region.StartLine != 7 {
+		t.Fatalf("expected region at line 7, got %+v", loc.Region)
+	}
+	if run.Results[0].Locations[0].PhysicalLocation.Region != nil {
+		t.Fatalf("name-rule findings should not carry a region")
+	}
+	if run.Results[2].RuleIndex != 1 {
+		t.Fatalf("expected repeated rule to reuse index 1, got %d", run.Results[2].RuleIndex)
+	}
+	if base := run.OriginalURIBaseIDs[sarifRootBase].URI; base != "file:///work/app/" {
+		t.Fatalf("unexpected base uri: %s", base)
+	}
+
+	var buf bytes.Buffer
+	if err := writeScanSARIF(&buf, ctx, nil); err != nil {
+		t.Fatalf("write sarif: %v", err)
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("decode sarif: %v", err)
+	}
+	runs := decoded["runs"].([]any)
+	if results := runs[0].(map[string]any)["results"].([]any); len(results) != 0 {
+		t.Fatalf("expected empty results array, got %v", results)
+	}
+}
@@ -0,0 +1,267 @@
+package domain
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	DefaultArchiveMaxDepth = 2
+	DefaultArchiveMaxBytes = 100 << 20
+	maxArchiveEntries      = 10000
+	archiveEntrySeparator  = "!/"
+)
+
+type archiveKind int
+
+const (
+	archiveNone archiveKind = iota
+	archiveZip
+	archiveTar
+	archiveTarGzip
+	archiveGzip
+)
+
+var errArchiveBudget = errors.New("archive scan budget exhausted")
+
+type archiveScan struct {
+	opts      ScanOptions
+	remaining int64
+	entries   int
+	parents   []string
+	visit     func(SensitiveReason)
+}
+
+func archiveKindOf(name string) archiveKind {
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(lower, ".zip"):
+		return archiveZip
+	case strings.HasSuffix(lower, ".tar"):
+		return archiveTar
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return archiveTarGzip
+	case strings.HasSuffix(lower, ".gz"):
+		return archiveGzip
+	default:
+		return archiveNone
+	}
+}
+
+func IsArchiveFile(name string) bool {
+	return archiveKindOf(name) != archiveNone
+}
+
+func looksSensitiveInArchive(filePath string, opts ScanOptions) (SensitiveReason, error) {
+	return walkArchiveFile(filePath, newArchiveScan(opts))
+}
+
+// visitArchiveFindings reports every match in the archive in a single pass,
+// instead of stopping at the first one.
+func visitArchiveFindings(filePath string, opts ScanOptions, visit func(SensitiveReason)) error {
+	scan := newArchiveScan(opts)
+	scan.visit = visit
+	_, err := walkArchiveFile(filePath, scan)
+	return err
+}
+
+func walkArchiveFile(filePath string, scan *archiveScan) (SensitiveReason, error) {
+	kind := archiveKindOf(filePath)
+	if kind == archiveNone || scan.opts.ArchiveMaxDepth <= 0 {
+		return SensitiveReason{}, nil
+	}
+
+	f, err := os.Open(filePath)
+	if err != nil {
+		return SensitiveReason{}, err
+	}
+	defer f.Close()
+
+	if kind == archiveZip {
+		info, err := f.Stat()
+		if err != nil {
+			return SensitiveReason{}, err
+		}
+		return scan.result(scan.zip(filePath, f, info.Size(), 1))
+	}
+	return scan.result(scan.stream(filePath, kind, f, 1))
+}
+
+func scanArchiveContent(filePath string, r io.Reader, opts ScanOptions) (SensitiveReason, error) {
+	kind := archiveKindOf(filePath)
+	if kind == archiveNone || opts.ArchiveMaxDepth <= 0 {
+		return SensitiveReason{}, nil
+	}
+	scan := newArchiveScan(opts)
+	return scan.result(scan.entryArchive(filePath, kind, r, 1))
+}
+
+func newArchiveScan(opts ScanOptions) *archiveScan {
+	remaining := opts.ArchiveMaxBytes
+	if remaining <= 0 {
+		remaining = DefaultArchiveMaxBytes
+	}
+	opts.IncludeSuppressed = false
+	return &archiveScan{opts: opts, remaining: remaining}
+}
+
+func (s *archiveScan) result(reason SensitiveReason, err error) (SensitiveReason, error) {
+	if errors.Is(err, errArchiveBudget) {
+		return reason, nil
+	}
+	var corrupt archiveFormatError
+	if errors.As(err, &corrupt) {
+		return reason, nil
+	}
+	return reason, err
+}
+
+type archiveFormatError struct{ err error }
+
+func (e archiveFormatError) Error() string { return e.err.Error() }
+
+func (s *archiveScan) zip(name string, r io.ReaderAt, size int64, depth int) (SensitiveReason, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return SensitiveReason{}, archiveFormatError{fmt.Errorf("open zip %s: %w", name, err)}
+	}
+	for _, file := range zr.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+		rc, err := file.Open()
+		if err != nil {
+			return SensitiveReason{}, archiveFormatError{fmt.Errorf("open %s in %s: %w", file.Name, name, err)}
+		}
+		reason, err := s.entry(name, file.Name, rc, depth)
+		rc.Close()
+		if err != nil || reason.Matched() {
+			return reason, err
+		}
+	}
+	return SensitiveReason{}, nil
+}
+
+func (s *archiveScan) stream(name string, kind archiveKind, r io.Reader, depth int) (SensitiveReason, error) {
+	if kind == archiveTarGzip || kind == archiveGzip {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return SensitiveReason{}, archiveFormatError{fmt.Errorf("open gzip %s: %w", name, err)}
+		}
+		defer gz.Close()
+		r = gz
+	}
+	if kind == archiveGzip {
+		inner := strings.TrimSuffix(path.Base(filepath.ToSlash(name)), path.Ext(name))
+		return s.entry(name, inner, r, depth)
+	}
+
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return SensitiveReason{}, nil
+		}
+		if err != nil {
+			return SensitiveReason{}, archiveFormatError{fmt.Errorf("read tar %s: %w", name, err)}
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		reason, err := s.entry(name, hdr.Name, tr, depth)
+		if err != nil || reason.Matched() {
+			return reason, err
+		}
+	}
+}
+
+func (s *archiveScan) entry(archiveName, entryName string, r io.Reader, depth int) (SensitiveReason, error) {
+	s.entries++
+	if s.entries > maxArchiveEntries || s.remaining <= 0 {
+		return SensitiveReason{}, errArchiveBudget
+	}
+	r = &budgetReader{r: r, remaining: &s.remaining}
+	entryPath := archiveName + archiveEntrySeparator + strings.TrimPrefix(entryName, "/")
+
+	reason, decided := detectBaselinedName(filepath.FromSlash(entryPath), s.opts)
+	if !decided {
+		var err error
+		switch kind := archiveKindOf(entryName); {
+		case kind != archiveNone && depth < s.opts.ArchiveMaxDepth:
+			s.parents = append(s.parents, entryName)
+			reason, err = s.entryArchive(entryPath, kind, r, depth+1)
+			s.parents = s.parents[:len(s.parents)-1]
+			return reason, err
+		case kind == archiveNone && contentScannable(entryName, s.opts) && s.visit != nil:
+			return SensitiveReason{}, scanContentMatches(entryPath, r, s.opts, func(match SensitiveReason, suppressed bool) bool {
+				if !suppressed {
+					s.visit(s.annotate(match, entryName))
+				}
+				return true
+			})
+		case kind == archiveNone && contentScannable(entryName, s.opts):
+			reason, err = scanContent(entryPath, r, s.opts)
+		}
+		if err != nil || !reason.Matched() {
+			return SensitiveReason{}, err
+		}
+	}
+	if !reason.Matched() {
+		return SensitiveReason{}, nil
+	}
+	reason = s.annotate(reason, entryName)
+	if s.visit != nil {
+		s.visit(reason)
+		return SensitiveReason{}, nil
+	}
+	return reason, nil
+}
+
+func (s *archiveScan) annotate(reason SensitiveReason, entryName string) SensitiveReason {
+	reason.Entry = strings.Join(append(append([]string(nil), s.parents...), entryName), archiveEntrySeparator)
+	reason.Source = false
+	reason.Suppressed = nil
+	return reason
+}
+
+func (s *archiveScan) entryArchive(name string, kind archiveKind, r io.Reader, depth int) (SensitiveReason, error) {
+	if kind != archiveZip {
+		return s.stream(name, kind, r, depth)
+	}
+	// zip needs random access, so buffer it, but never past the budget.
+	limit := s.remaining
+	data, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return SensitiveReason{}, err
+	}
+	if int64(len(data)) > limit {
+		return SensitiveReason{}, errArchiveBudget
+	}
+	return s.zip(name, bytes.NewReader(data), int64(len(data)), depth)
+}
+
+type budgetReader struct {
+	r         io.Reader
+	remaining *int64
+}
+
+func (b *budgetReader) Read(p []byte) (int, error) {
+	if *b.remaining <= 0 {
+		return 0, errArchiveBudget
+	}
+	if int64(len(p)) > *b.remaining {
+		p = p[:*b.remaining]
+	}
+	n, err := b.r.Read(p)
+	*b.remaining -= int64(n)
+	return n, err
+}
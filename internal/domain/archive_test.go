@@ -0,0 +1,126 @@
+package domain
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeZip(t *testing.T, files map[string][]byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, data := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("zip create %s: %v", name, err)
+		}
+		if _, err := w.Write(data); err != nil {
+			t.Fatalf("zip write %s: %v", name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("zip close: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func writeTarGz(t *testing.T, files map[string][]byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, data := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o600, Size: int64(len(data)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatalf("tar header %s: %v", name, err)
+		}
+		if _, err := tw.Write(data); err != nil {
+			t.Fatalf("tar write %s: %v", name, err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("tar close: %v", err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatalf("gzip close: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestArchiveEntriesAreScanned(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, data []byte) string {
+		t.Helper()
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+
+	clean := write("docs.zip", writeZip(t, map[string][]byte{"README.txt": []byte("hello\n")}))
+	byName := write("config-backup.zip", writeZip(t, map[string][]byte{"app/.env": []byte("A=1\n")}))
+	byContent := write("certs.tar.gz", writeTarGz(t, map[string][]byte{
+		"notes.txt":   []byte("nothing\n"),
+		"config.yaml": []byte("db:\n  password: hunter2\n"),
+	}))
+	nested := write("outer.tar.gz", writeTarGz(t, map[string][]byte{
+		"inner.zip": writeZip(t, map[string][]byte{"id_rsa": []byte("x")}),
+	}))
+
+	opts := DefaultScanOptions()
+	for _, tc := range []struct {
+		path  string
+		rule  string
+		entry string
+		line  int
+	}{
+		{path: clean},
+		{path: byName, rule: RuleExactName, entry: "app/.env"},
+		{path: byContent, rule: "generic-secret-assignment", entry: "config.yaml", line: 2},
+		{path: nested, rule: RuleExactName, entry: "inner.zip!/id_rsa"},
+	} {
+		reason, err := detectSensitiveFile(tc.path, opts)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.path, err)
+		}
+		if reason.Rule != tc.rule || reason.Entry != tc.entry || reason.Line != tc.line {
+			t.Fatalf("%s: unexpected reason %+v", filepath.Base(tc.path), reason)
+		}
+	}
+
+	opts.ArchiveMaxDepth = 1
+	if reason, err := detectSensitiveFile(nested, opts); err != nil || reason.Matched() {
+		t.Fatalf("expected depth limit to stop nested scan, got %+v, %v", reason, err)
+	}
+
+	opts = DefaultScanOptions()
+	opts.ArchiveMaxBytes = 4
+	if reason, err := detectSensitiveFile(byContent, opts); err != nil || reason.Matched() {
+		t.Fatalf("expected byte budget to stop scan, got %+v, %v", reason, err)
+	}
+}
+
+type endlessReader struct{ read int64 }
+
+func (r *endlessReader) Read(p []byte) (int, error) {
+	r.read += int64(len(p))
+	return len(p), nil
+}
+
+func TestZipContentReadStopsAtBudget(t *testing.T) {
+	opts := DefaultScanOptions()
+	opts.ArchiveMaxBytes = 1 << 16
+	r := &endlessReader{}
+	reason, err := scanArchiveContent("blob.zip", r, opts)
+	if err != nil || reason.Matched() {
+		t.Fatalf("expected an oversized zip to be skipped, got %+v, %v", reason, err)
+	}
+	if r.read > 2*opts.ArchiveMaxBytes {
+		t.Fatalf("read %d bytes for a %d byte budget", r.read, opts.ArchiveMaxBytes)
+	}
+}
@@ -0,0 +1,197 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	BaselineFileName = ".secretvault-baseline.json"
+	baselineVersion  = 1
+)
+
+type BaselineEntry struct {
+	Fingerprint string `json:"fingerprint"`
+	Path        string `json:"path"`
+	RuleID      string `json:"rule_id"`
+	Line        int    `json:"line,omitempty"`
+}
+
+type BaselineFile struct {
+	Version     int             `json:"version"`
+	GeneratedAt string          `json:"generated_at"`
+	Findings    []BaselineEntry `json:"findings"`
+}
+
+type Baseline struct {
+	Root         string
+	fingerprints map[string]struct{}
+}
+
+func NewBaseline(root string) *Baseline {
+	return &Baseline{Root: filepath.Clean(root), fingerprints: map[string]struct{}{}}
+}
+
+func BaselinePath(root string) string {
+	return filepath.Join(root, BaselineFileName)
+}
+
+func LoadBaseline(root string) (*Baseline, error) {
+	baseline := NewBaseline(root)
+	data, err := os.ReadFile(BaselinePath(root))
+	if errors.Is(err, fs.ErrNotExist) {
+		return baseline, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var file BaselineFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", BaselineFileName, err)
+	}
+	if file.Version != baselineVersion {
+		return nil, fmt.Errorf("unsupported %s version %d", BaselineFileName, file.Version)
+	}
+	for _, entry := range file.Findings {
+		baseline.fingerprints[entry.Fingerprint] = struct{}{}
+	}
+	return baseline, nil
+}
+
+func WriteBaseline(root string, entries []BaselineEntry) error {
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Path != entries[j].Path {
+			return entries[i].Path < entries[j].Path
+		}
+		if entries[i].Line != entries[j].Line {
+			return entries[i].Line < entries[j].Line
+		}
+		return entries[i].Fingerprint < entries[j].Fingerprint
+	})
+	file := BaselineFile{
+		Version:     baselineVersion,
+		GeneratedAt: time.Now().UTC().Format(time.RFC3339),
+		Findings:    entries,
+	}
+	if file.Findings == nil {
+		file.Findings = []BaselineEntry{}
+	}
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+	return WriteAtomic(BaselinePath(root), append(data, '\n'), 0o644)
+}
+
+func (b *Baseline) Len() int {
+	if b == nil {
+		return 0
+	}
+	return len(b.fingerprints)
+}
+
+func (b *Baseline) Contains(fingerprint string) bool {
+	if b == nil || fingerprint == "" {
+		return false
+	}
+	_, ok := b.fingerprints[fingerprint]
+	return ok
+}
+
+func (b *Baseline) Entry(path string, reason SensitiveReason) BaselineEntry {
+	return BaselineEntry{
+		Fingerprint: reason.Fingerprint,
+		Path:        b.relativePath(path),
+		RuleID:      reason.Rule,
+		Line:        reason.Line,
+	}
+}
+
+func (b *Baseline) fingerprint(path, rule, line string) string {
+	if b == nil {
+		return ""
+	}
+	h := sha256.Sum256([]byte(b.relativePath(path) + "\x00" + rule + "\x00" + strings.TrimSpace(line)))
+	return hex.EncodeToString(h[:])
+}
+
+func (b *Baseline) relativePath(path string) string {
+	if rel, err := filepath.Rel(b.Root, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(rel)
+	}
+	return filepath.ToSlash(path)
+}
+
+func (b *Baseline) digest() string {
+	if b.Len() == 0 {
+		return ""
+	}
+	h := sha256.New()
+	for _, fp := range SortedKeys(b.fingerprints) {
+		h.Write([]byte(fp))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func SensitiveFindings(path string, opts ScanOptions) ([]SensitiveReason, error) {
+	var findings []SensitiveReason
+	reason, decided := detectSensitiveName(path)
+	if reason.Matched() {
+		reason.Fingerprint = opts.Baseline.fingerprint(path, reason.Rule, "")
+		findings = append(findings, reason)
+	}
+	if decided && !reason.Matched() {
+		return findings, nil
+	}
+	if IsArchiveFile(path) {
+		archived, err := sensitiveArchiveFindings(path, opts)
+		return append(findings, archived...), err
+	}
+	if !contentScannable(path, opts) {
+		return findings, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	err = scanContentMatches(path, f, opts, func(match SensitiveReason, suppressed bool) bool {
+		if !suppressed {
+			findings = append(findings, match)
+		}
+		return true
+	})
+	return findings, err
+}
+
+func sensitiveArchiveFindings(path string, opts ScanOptions) ([]SensitiveReason, error) {
+	if opts.Baseline == nil {
+		return nil, nil
+	}
+	opts.Baseline = NewBaseline(opts.Baseline.Root)
+
+	var findings []SensitiveReason
+	seen := map[string]struct{}{}
+	err := visitArchiveFindings(path, opts, func(reason SensitiveReason) {
+		if _, dup := seen[reason.Fingerprint]; !dup {
+			seen[reason.Fingerprint] = struct{}{}
+			findings = append(findings, reason)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return findings, nil
+}
@@ -0,0 +1,94 @@
+package domain
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBaselineSuppressesKnownFindingsOnly(t *testing.T) {
+	dir := t.TempDir()
+	fixture := filepath.Join(dir, "fixtures", "test.yaml")
+	if err := os.MkdirAll(filepath.Dir(fixture), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(fixture, []byte("db:\n  password: dummy\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := DefaultScanOptions()
+	opts.Baseline = NewBaseline(dir)
+	findings, err := SensitiveFindings(fixture, opts)
+	if err != nil {
+		t.Fatalf("findings: %v", err)
+	}
+	if len(findings) != 1 || findings[0].Fingerprint == "" {
+		t.Fatalf("expected one fingerprinted finding, got %+v", findings)
+	}
+	if err := WriteBaseline(dir, []BaselineEntry{opts.Baseline.Entry(fixture, findings[0])}); err != nil {
+		t.Fatalf("write baseline: %v", err)
+	}
+
+	baseline, err := LoadBaseline(dir)
+	if err != nil {
+		t.Fatalf("load baseline: %v", err)
+	}
+	opts.Baseline = baseline
+	detections, err := ScanSensitiveFiles([]string{dir}, opts)
+	if err != nil {
+		t.Fatalf("scan: %v", err)
+	}
+	if len(detections) != 0 {
+		t.Fatalf("expected baseline to suppress the fixture, got %+v", detections)
+	}
+
+	if err := os.WriteFile(fixture, []byte("# moved down a line\ndb:\n  password: dummy\n  api_key: real\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	detections, err = ScanSensitiveFiles([]string{dir}, opts)
+	if err != nil {
+		t.Fatalf("rescan: %v", err)
+	}
+	if len(detections) != 1 || detections[0].Reason.Line != 4 {
+		t.Fatalf("expected only the new api_key finding on line 4, got %+v", detections)
+	}
+}
+
+func TestSensitiveFindingsCollectsEveryArchiveMatch(t *testing.T) {
+	dir := t.TempDir()
+	inner := writeZip(t, map[string][]byte{"deploy/.env": []byte("A=1\n")})
+	archive := filepath.Join(dir, "backup.zip")
+	data := writeZip(t, map[string][]byte{
+		"config.ini":  []byte("[db]\npassword = one\n[api]\ntoken = two\n"),
+		"notes.txt":   []byte("nothing\n"),
+		"nested.zip":  inner,
+		"server.pem":  []byte("x\n"),
+		"README.md":   []byte("hello\n"),
+		"other.ini":   []byte("secret_key = three\n"),
+		"plain.yaml":  []byte("name: app\n"),
+		"keys/id_rsa": []byte("x\n"),
+	})
+	if err := os.WriteFile(archive, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := DefaultScanOptions()
+	opts.Baseline = NewBaseline(dir)
+	findings, err := SensitiveFindings(archive, opts)
+	if err != nil {
+		t.Fatalf("findings: %v", err)
+	}
+	entries := map[string]int{}
+	for _, f := range findings {
+		entries[f.Entry]++
+	}
+	want := map[string]int{"config.ini": 2, "other.ini": 1, "server.pem": 1, "keys/id_rsa": 1, "nested.zip!/deploy/.env": 1}
+	if len(entries) != len(want) {
+		t.Fatalf("expected entries %v, got %v", want, entries)
+	}
+	for entry, n := range want {
+		if entries[entry] != n {
+			t.Fatalf("expected %d finding(s) for %s, got %v", n, entry, entries)
+		}
+	}
+}
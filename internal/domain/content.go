@@ -0,0 +1,260 @@
+package domain
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var (
+	suppressionMarker = regexp.MustCompile(`secretvault:ignore(-next-line)?\b`)
+	excerptKeyPattern = regexp.MustCompile("([A-Za-z_][A-Za-z0-9_.\\-]*)[\"']?\\s*(?::=|=>|[:=])\\s*[\"'`]?\\s*$")
+)
+
+const (
+	DefaultMaxContentBytes = 10 << 20
+	maxContentLineBytes    = 1 << 20
+	binarySniffBytes       = 8000
+)
+
+func looksSensitiveByContent(path string, opts ScanOptions) (SensitiveReason, error) {
+	if !contentScannable(path, opts) {
+		return SensitiveReason{}, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return SensitiveReason{}, err
+	}
+	defer f.Close()
+
+	return scanContent(path, f, opts)
+}
+
+func scanContent(path string, r io.Reader, opts ScanOptions) (SensitiveReason, error) {
+	var found, entropyReason SensitiveReason
+	var suppressed []SensitiveReason
+	err := scanContentMatches(path, r, opts, func(match SensitiveReason, isSuppressed bool) bool {
+		switch {
+		case isSuppressed:
+			if opts.IncludeSuppressed {
+				suppressed = append(suppressed, match)
+			}
+		case opts.Baseline.Contains(match.Fingerprint):
+		case match.Rule == HighEntropyDetectorID:
+			if !entropyReason.Matched() {
+				entropyReason = match
+			}
+		case !found.Matched():
+			found = match
+		}
+		return opts.IncludeSuppressed || !found.Matched()
+	})
+	if err != nil {
+		return SensitiveReason{}, err
+	}
+	if !found.Matched() {
+		found = entropyReason
+	}
+	found.Suppressed = suppressed
+	return found, nil
+}
+
+func scanContentMatches(path string, r io.Reader, opts ScanOptions, visit func(match SensitiveReason, suppressed bool) bool) error {
+	if opts.MaxContentBytes > 0 {
+		r = io.LimitReader(r, opts.MaxContentBytes)
+	}
+
+	if isStructuredCandidate(path) {
+		data, err := io.ReadAll(io.LimitReader(r, maxStructuredDocumentBytes+1))
+		if err != nil {
+			return err
+		}
+		if len(data) <= maxStructuredDocumentBytes && !IsBinaryContent(data[:min(len(data), binarySniffBytes)]) {
+			for _, match := range detectStructuredSecrets(path, data) {
+				match.reason.Fingerprint = opts.Baseline.fingerprint(path, match.reason.Rule, match.locator)
+				if !visit(match.reason, false) {
+					return nil
+				}
+			}
+		}
+		r = io.MultiReader(bytes.NewReader(data), r)
+	}
+
+	br := bufio.NewReaderSize(r, binarySniffBytes)
+	head, err := br.Peek(binarySniffBytes)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, bufio.ErrBufferFull) {
+		return err
+	}
+	if len(head) == 0 || IsBinaryContent(head) {
+		return nil
+	}
+
+	source := IsSourceFile(path)
+	example, schema := IsDotenvExample(path), IsDotenvSchema(path)
+	lines := contentLineReader{br: br}
+	lineNo := 0
+	suppressed, suppressNext := false, false
+	for {
+		line, cont, ok := lines.next()
+		if !ok {
+			break
+		}
+		if !cont {
+			lineNo++
+			suppressed, suppressNext = suppressNext, false
+		}
+		if marker := suppressionMarker.FindStringSubmatch(line); marker != nil {
+			if marker[1] != "" {
+				suppressNext = true
+			} else {
+				suppressed = true
+			}
+		}
+
+		if example && isDotenvPlaceholder(line) {
+			continue
+		}
+		if schema {
+			if rules, ok := dotenvSchemaRules(line); ok {
+				line = rules
+			}
+		}
+
+		var match SensitiveReason
+		if detector, ok := matchSecretDetector(line, source); ok {
+			match = SensitiveReason{
+				Rule:     detector.ID,
+				Pattern:  detector.Description,
+				Severity: detector.Severity,
+				Line:     lineNo,
+				Excerpt:  redactLine(detector.ID, line, detector.Pattern.FindStringIndex(line)),
+			}
+		} else if source {
+			continue
+		} else if value, _, ok := FindHighEntropyValue(line, opts.EntropyThreshold, opts.EntropyMinLength); ok {
+			start := strings.Index(line, value)
+			match = SensitiveReason{
+				Rule:     HighEntropyDetectorID,
+				Pattern:  "high-entropy value",
+				Severity: SeverityMedium,
+				Line:     lineNo,
+				Excerpt:  redactLine(HighEntropyDetectorID, line, []int{start, start + len(value)}),
+			}
+		} else {
+			continue
+		}
+		match.Source = source
+		match.Fingerprint = opts.Baseline.fingerprint(path, match.Rule, line)
+		if !visit(match, suppressed) {
+			return nil
+		}
+	}
+	return lines.Err()
+}
+
+type contentLineReader struct {
+	br      *bufio.Reader
+	buf     []byte
+	partial bool
+	err     error
+}
+
+// next returns the next line without its terminator. Lines longer than
+// maxContentLineBytes come back in pieces, with cont set on every piece after
+// the first, so a huge minified or base64 line does not end the scan.
+func (r *contentLineReader) next() (string, bool, bool) {
+	if r.err != nil {
+		return "", false, false
+	}
+	cont := r.partial
+	r.buf = r.buf[:0]
+	for {
+		chunk, err := r.br.ReadSlice('\n')
+		r.buf = append(r.buf, chunk...)
+		if errors.Is(err, bufio.ErrBufferFull) {
+			if len(r.buf) < maxContentLineBytes {
+				continue
+			}
+			r.partial = true
+			return string(r.buf), cont, true
+		}
+		r.partial = false
+		if err != nil {
+			r.err = err
+			if len(r.buf) == 0 {
+				return "", false, false
+			}
+		}
+		line := bytes.TrimSuffix(bytes.TrimSuffix(r.buf, []byte("\n")), []byte("\r"))
+		return string(line), cont, true
+	}
+}
+
+func (r *contentLineReader) Err() error {
+	if errors.Is(r.err, io.EOF) {
+		return nil
+	}
+	return r.err
+}
+
+func IsBinaryContent(head []byte) bool {
+	return bytes.IndexByte(head, 0) >= 0
+}
+
+// redactLine names the key a match was assigned to, or the rule when there is
+// none. Raw line text never ends up in the excerpt, since it may hold other
+// secrets and is cached in the scan index.
+func redactLine(rule, line string, loc []int) string {
+	line = strings.TrimRight(line, "\r")
+	if len(loc) != 2 || loc[0] < 0 || loc[1] > len(line) {
+		return ""
+	}
+
+	start, end := loc[0], loc[1]
+	if end > start && (line[end-1] == ':' || line[end-1] == '=') {
+		start = end
+	}
+	key := ""
+	if m := excerptKeyPattern.FindStringSubmatch(line[:start]); m != nil {
+		key = m[1]
+	}
+	if key == "" {
+		return rule + ": ****"
+	}
+	if len(key) > 60 {
+		key = key[:57] + "..."
+	}
+	return key + " = ****"
+}
+
+func shouldScanFileContent(path string) bool {
+	base := strings.ToLower(filepath.Base(path))
+	if _, skip := ContentScanSkipNames[base]; skip {
+		return false
+	}
+	if strings.HasPrefix(base, ".env") {
+		return true
+	}
+	if _, ok := ContentScanNames[base]; ok {
+		return true
+	}
+
+	ext := strings.ToLower(filepath.Ext(base))
+	_, ok := ContentScanExtensions[ext]
+	return ok
+}
+
+func IsSourceFile(path string) bool {
+	_, ok := SourceScanExtensions[strings.ToLower(filepath.Ext(path))]
+	return ok
+}
+
+func contentScannable(path string, opts ScanOptions) bool {
+	return shouldScanFileContent(path) || isStructuredCandidate(path) || (opts.ScanSource && IsSourceFile(path))
+}
@@ -0,0 +1,112 @@
+package domain
+
+import "regexp"
+
+const (
+	SeverityLow      = "low"
+	SeverityMedium   = "medium"
+	SeverityHigh     = "high"
+	SeverityCritical = "critical"
+
+	HighEntropyDetectorID = "high-entropy-value"
+)
+
+type SecretDetector struct {
+	ID          string
+	Description string
+	Severity    string
+	Pattern     *regexp.Regexp
+	ConfigOnly  bool
+}
+
+var SecretDetectors = []SecretDetector{
+	{
+		ID:          "private-key-block",
+		Description: "PEM private key block",
+		Severity:    SeverityCritical,
+		Pattern:     regexp.MustCompile(`-----BEGIN ((RSA|EC|DSA|OPENSSH|PGP|ENCRYPTED) )?PRIVATE KEY( BLOCK)?-----`),
+	},
+	{
+		ID:          "aws-access-key-id",
+		Description: "AWS access key ID",
+		Severity:    SeverityCritical,
+		Pattern:     regexp.MustCompile(`\b(AKIA|ASIA|ABIA|ACCA)[0-9A-Z]{16}\b`),
+	},
+	{
+		ID:          "aws-secret-access-key",
+		Description: "AWS secret access key",
+		Severity:    SeverityCritical,
+		Pattern:     regexp.MustCompile(`(?i)aws_secret_access_key`),
+	},
+	{
+		ID:          "github-token",
+		Description: "GitHub personal access or app token",
+		Severity:    SeverityCritical,
+		Pattern:     regexp.MustCompile(`\b(gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`),
+	},
+	{
+		ID:          "gitlab-token",
+		Description: "GitLab personal access token",
+		Severity:    SeverityCritical,
+		Pattern:     regexp.MustCompile(`\bglpat-[A-Za-z0-9_\-]{20,}`),
+	},
+	{
+		ID:          "slack-webhook",
+		Description: "Slack incoming webhook URL",
+		Severity:    SeverityHigh,
+		Pattern:     regexp.MustCompile(`https://hooks\.slack\.com/(services|workflows)/[A-Za-z0-9_/]+`),
+	},
+	{
+		ID:          "slack-token",
+		Description: "Slack API token",
+		Severity:    SeverityHigh,
+		Pattern:     regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`),
+	},
+	{
+		ID:          "stripe-secret-key",
+		Description: "Stripe secret or restricted key",
+		Severity:    SeverityCritical,
+		Pattern:     regexp.MustCompile(`\b(sk|rk)_(live|test)_[A-Za-z0-9]{16,}`),
+	},
+	{
+		ID:          "google-service-account",
+		Description: "Google service account JSON key",
+		Severity:    SeverityCritical,
+		Pattern:     regexp.MustCompile(`"type"\s*:\s*"service_account"`),
+	},
+	{
+		ID:          "jwt",
+		Description: "JSON Web Token",
+		Severity:    SeverityMedium,
+		Pattern:     regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{8,}\.eyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{8,}`),
+	},
+	{
+		ID:          "database-url-password",
+		Description: "database URL with embedded password",
+		Severity:    SeverityHigh,
+		Pattern:     regexp.MustCompile(`(?i)\b(postgres(ql)?|mysql|mariadb|mongodb(\+srv)?|rediss?|amqps?|mssql|sqlserver)://[^:/\s@]+:[^@/\s]+@`),
+	},
+	{
+		ID:          "generic-secret-assignment",
+		Description: "secret-like key assignment",
+		Severity:    SeverityMedium,
+		Pattern:     regexp.MustCompile(`(?i)(api[_-]?key|token|password|private[_-]?key|secret[_-]?(key|token|value))["']?\s*[:=]`),
+		ConfigOnly:  true,
+	},
+}
+
+func MatchSecretDetector(line string) (SecretDetector, bool) {
+	return matchSecretDetector(line, false)
+}
+
+func matchSecretDetector(line string, source bool) (SecretDetector, bool) {
+	for _, detector := range SecretDetectors {
+		if source && detector.ConfigOnly {
+			continue
+		}
+		if detector.Pattern.MatchString(line) {
+			return detector, true
+		}
+	}
+	return SecretDetector{}, false
+}
@@ -0,0 +1,274 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const DiffContextLines = 3
+
+const maxDiffEdits = 1000
+
+type KeyChange struct {
+	Key    string
+	Change string
+}
+
+const (
+	KeyAdded   = "added"
+	KeyRemoved = "removed"
+	KeyChanged = "changed"
+)
+
+var genericAssignment = regexp.MustCompile(`^\s*(?:export\s+)?([A-Za-z_][\w.\-]*)\s*[:=]\s*(.*)$`)
+
+type diffOp struct {
+	kind byte
+	line string
+}
+
+func UnifiedDiff(oldName, newName string, oldData, newData []byte, context int) string {
+	oldLines, newLines := splitDiffLines(oldData), splitDiffLines(newData)
+	ops := diffLines(oldLines, newLines)
+
+	var b strings.Builder
+	hunks := diffHunks(ops, context)
+	if len(hunks) == 0 {
+		return ""
+	}
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", oldName, newName)
+	for _, h := range hunks {
+		fmt.Fprintf(&b, "@@ -%s +%s @@\n", hunkRange(h.oldStart, h.oldCount), hunkRange(h.newStart, h.newCount))
+		for _, op := range ops[h.from:h.to] {
+			b.WriteByte(op.kind)
+			b.WriteString(op.line)
+			b.WriteByte('\n')
+		}
+	}
+	return b.String()
+}
+
+func splitDiffLines(data []byte) []string {
+	text := strings.TrimSuffix(string(data), "\n")
+	if text == "" && len(data) <= 1 {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
+
+// diffLines is Myers' O(ND) shortest edit script over the lines between the
+// common prefix and suffix. Each step only keeps the diagonals it reached, and
+// past maxDiffEdits the middle is emitted as one replacement instead.
+func diffLines(a, b []string) []diffOp {
+	var ops []diffOp
+	for len(a) > 0 && len(b) > 0 && a[0] == b[0] {
+		ops = append(ops, diffOp{' ', a[0]})
+		a, b = a[1:], b[1:]
+	}
+	suffix := 0
+	for suffix < len(a) && suffix < len(b) && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	ops = append(ops, myersDiff(a[:len(a)-suffix], b[:len(b)-suffix])...)
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, diffOp{' ', line})
+	}
+	return ops
+}
+
+func myersDiff(a, b []string) []diffOp {
+	n, m := len(a), len(b)
+	maxD := min(n+m, maxDiffEdits)
+	offset := maxD + 1
+	v := make([]int, 2*offset+1)
+	var trace [][]int
+	for d := 0; d <= maxD; d++ {
+		trace = append(trace, append([]int(nil), v[offset-d:offset+d+1]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return backtrackDiff(a, b, trace, d)
+			}
+		}
+	}
+	ops := make([]diffOp, 0, n+m)
+	for _, line := range a {
+		ops = append(ops, diffOp{'-', line})
+	}
+	for _, line := range b {
+		ops = append(ops, diffOp{'+', line})
+	}
+	return ops
+}
+
+// backtrackDiff walks trace back from depth; trace[d] holds diagonals -d..d.
+func backtrackDiff(a, b []string, trace [][]int, depth int) []diffOp {
+	x, y := len(a), len(b)
+	var ops []diffOp
+	for d := depth; d > 0; d-- {
+		v := trace[d]
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && v[d+k-1] < v[d+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[d+prevK]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x--
+			y--
+			ops = append(ops, diffOp{' ', a[x]})
+		}
+		if x == prevX {
+			y--
+			ops = append(ops, diffOp{'+', b[y]})
+		} else {
+			x--
+			ops = append(ops, diffOp{'-', a[x]})
+		}
+	}
+	for x > 0 && y > 0 {
+		x--
+		y--
+		ops = append(ops, diffOp{' ', a[x]})
+	}
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+	return ops
+}
+
+type diffHunk struct {
+	from, to           int
+	oldStart, oldCount int
+	newStart, newCount int
+}
+
+func diffHunks(ops []diffOp, context int) []diffHunk {
+	context = max(0, context)
+	var hunks []diffHunk
+	oldLine, newLine := 1, 1
+	oldAt := make([]int, len(ops)+1)
+	newAt := make([]int, len(ops)+1)
+	for i, op := range ops {
+		oldAt[i], newAt[i] = oldLine, newLine
+		if op.kind != '+' {
+			oldLine++
+		}
+		if op.kind != '-' {
+			newLine++
+		}
+	}
+	oldAt[len(ops)], newAt[len(ops)] = oldLine, newLine
+
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			i++
+			continue
+		}
+		end := i + 1
+		for j := end; j < len(ops); j++ {
+			if ops[j].kind == ' ' {
+				continue
+			}
+			if j-end > 2*context {
+				break
+			}
+			end = j + 1
+		}
+		from, to := max(0, i-context), min(len(ops), end+context)
+		hunks = append(hunks, diffHunk{
+			from: from, to: to,
+			oldStart: oldAt[from], oldCount: oldAt[to] - oldAt[from],
+			newStart: newAt[from], newCount: newAt[to] - newAt[from],
+		})
+		i = end
+	}
+	return hunks
+}
+
+func hunkRange(start, count int) string {
+	if count == 0 {
+		start--
+	}
+	if count == 1 {
+		return strconv.Itoa(start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+func RedactedKeyDiff(path string, oldData, newData []byte) ([]KeyChange, bool) {
+	oldKeys, ok := keyedValues(path, oldData)
+	if !ok {
+		return nil, false
+	}
+	newKeys, ok := keyedValues(path, newData)
+	if !ok {
+		return nil, false
+	}
+
+	var changes []KeyChange
+	for key, oldValue := range oldKeys {
+		newValue, exists := newKeys[key]
+		switch {
+		case !exists:
+			changes = append(changes, KeyChange{Key: key, Change: KeyRemoved})
+		case newValue != oldValue:
+			changes = append(changes, KeyChange{Key: key, Change: KeyChanged})
+		}
+	}
+	for key := range newKeys {
+		if _, exists := oldKeys[key]; !exists {
+			changes = append(changes, KeyChange{Key: key, Change: KeyAdded})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Key < changes[j].Key })
+	return changes, true
+}
+
+func keyedValues(path string, data []byte) (map[string]string, bool) {
+	if IsDotenvFile(path) {
+		file, err := ParseDotenv(data)
+		if err != nil {
+			return nil, false
+		}
+		return file.Values(), true
+	}
+	if IsStructuredLockFile(path) {
+		var doc yaml.Node
+		if err := yaml.Unmarshal(data, &doc); err == nil && doc.Kind == yaml.DocumentNode {
+			values := map[string]string{}
+			_ = walkStructuredLeaves(&doc, func(pointer string, leaf *yaml.Node) error {
+				values[pointer] = leaf.Tag + ":" + leaf.Value
+				return nil
+			})
+			return values, true
+		}
+	}
+
+	values := map[string]string{}
+	for _, line := range strings.Split(string(data), "\n") {
+		if m := genericAssignment.FindStringSubmatch(line); m != nil {
+			values[m[1]] = strings.TrimSpace(m[2])
+		}
+	}
+	return values, len(values) > 0
+}
@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiffHunks(t *testing.T) {
+	oldData := []byte("a\nb\nc\nd\ne\nf\ng\nh\ni\nj\n")
+	newData := []byte("a\nB\nc\nd\ne\nf\ng\nh\ni\nj\nk\n")
+
+	got := UnifiedDiff("x (vault)", "x", oldData, newData, 1)
+	want := "--- x (vault)\n+++ x\n" +
+		"@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n" +
+		"@@ -10 +10,2 @@\n j\n+k\n"
+	if got != want {
+		t.Fatalf("unexpected diff:\n%s\nwant:\n%s", got, want)
+	}
+	if got := UnifiedDiff("x (vault)", "x", oldData, newData, -1); got != UnifiedDiff("x (vault)", "x", oldData, newData, 0) {
+		t.Fatalf("expected negative context to behave like zero, got:\n%s", got)
+	}
+	if UnifiedDiff("x", "x", oldData, oldData, 3) != "" {
+		t.Fatal("expected no diff for identical content")
+	}
+}
+
+func TestUnifiedDiffReplacesMiddlePastEditLimit(t *testing.T) {
+	var oldData, newData strings.Builder
+	oldData.WriteString("head\n")
+	newData.WriteString("head\n")
+	for i := 0; i < maxDiffEdits; i++ {
+		fmt.Fprintf(&oldData, "old %d\n", i)
+		fmt.Fprintf(&newData, "new %d\n", i)
+	}
+	oldData.WriteString("tail\n")
+	newData.WriteString("tail\n")
+
+	got := UnifiedDiff("x (vault)", "x", []byte(oldData.String()), []byte(newData.String()), 1)
+	lines := strings.Split(strings.TrimSuffix(got, "\n"), "\n")
+	if header := fmt.Sprintf("@@ -1,%d +1,%d @@", maxDiffEdits+2, maxDiffEdits+2); lines[2] != header {
+		t.Fatalf("expected a single hunk %q, got %q", header, lines[2])
+	}
+	if lines[3] != " head" || lines[4] != "-old 0" || lines[3+maxDiffEdits+1] != "+new 0" || lines[len(lines)-1] != " tail" {
+		t.Fatalf("expected removals then additions between the shared lines, got %q ... %q", lines[3:6], lines[len(lines)-2:])
+	}
+}
+
+func TestRedactedKeyDiff(t *testing.T) {
+	oldData := []byte("A=1\nB=secret\nC=keep\n")
+	newData := []byte("A=1\nB=rotated\nD=new\nC=keep\n")
+	changes, ok := RedactedKeyDiff(".env", oldData, newData)
+	if !ok {
+		t.Fatal("expected keyed comparison for dotenv")
+	}
+	want := []KeyChange{{Key: "B", Change: KeyChanged}, {Key: "D", Change: KeyAdded}}
+	if !reflect.DeepEqual(changes, want) {
+		t.Fatalf("expected %+v, got %+v", want, changes)
+	}
+
+	changes, ok = RedactedKeyDiff("secrets.yaml", []byte("db:\n  password: a\n"), []byte("db:\n  user: x\n"))
+	want = []KeyChange{{Key: "/db/password", Change: KeyRemoved}, {Key: "/db/user", Change: KeyAdded}}
+	if !ok || !reflect.DeepEqual(changes, want) {
+		t.Fatalf("expected %+v, got %+v", want, changes)
+	}
+
+	if _, ok := RedactedKeyDiff("id_rsa", []byte("-----BEGIN\n"), []byte("-----END\n")); ok {
+		t.Fatal("expected non key-value content to be reported without keys")
+	}
+}
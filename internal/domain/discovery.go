@@ -1,77 +1,186 @@
 package domain
 
 import (
-	"errors"
+	"bytes"
+	"context"
+	"fmt"
 	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
 	"strings"
 )
 
+type ScanOptions struct {
+	NoIgnore          bool
+	EntropyThreshold  float64
+	EntropyMinLength  int
+	MaxContentBytes   int64
+	Workers           int
+	IndexPath         string
+	RebuildIndex      bool
+	Baseline          *Baseline
+	IncludeSuppressed bool
+	ArchiveMaxDepth   int
+	ArchiveMaxBytes   int64
+	ScanSource        bool
+	LockSource        bool
+}
+
+const (
+	RuleExactName    = "exact-name"
+	RuleEnvFile      = "env-file"
+	RuleSuffix       = "sensitive-suffix"
+	RuleSensitiveDir = "sensitive-dir"
+)
+
+type SensitiveReason struct {
+	Rule        string            `json:"rule,omitempty"`
+	Pattern     string            `json:"pattern,omitempty"`
+	Severity    string            `json:"severity,omitempty"`
+	Line        int               `json:"line,omitempty"`
+	Excerpt     string            `json:"excerpt,omitempty"`
+	Fingerprint string            `json:"fingerprint,omitempty"`
+	Entry       string            `json:"entry,omitempty"`
+	Source      bool              `json:"source,omitempty"`
+	Suppressed  []SensitiveReason `json:"suppressed,omitempty"`
+}
+
+type Detection struct {
+	Path   string
+	Reason SensitiveReason
+}
+
+func DefaultScanOptions() ScanOptions {
+	opts := ScanOptions{
+		EntropyThreshold: DefaultEntropyThreshold,
+		EntropyMinLength: DefaultEntropyMinLength,
+		MaxContentBytes:  DefaultMaxContentBytes,
+		Workers:          runtime.NumCPU(),
+		ArchiveMaxDepth:  DefaultArchiveMaxDepth,
+		ArchiveMaxBytes:  DefaultArchiveMaxBytes,
+	}
+	if v, err := strconv.ParseFloat(strings.TrimSpace(os.Getenv("SECRETVAULT_ENTROPY_THRESHOLD")), 64); err == nil {
+		opts.EntropyThreshold = v
+	}
+	if v, err := strconv.Atoi(strings.TrimSpace(os.Getenv("SECRETVAULT_ENTROPY_MIN_LENGTH"))); err == nil && v > 0 {
+		opts.EntropyMinLength = v
+	}
+	if v, err := strconv.ParseInt(strings.TrimSpace(os.Getenv("SECRETVAULT_SCAN_MAX_BYTES")), 10, 64); err == nil {
+		opts.MaxContentBytes = v
+	}
+	if v, err := strconv.Atoi(strings.TrimSpace(os.Getenv("SECRETVAULT_SCAN_WORKERS"))); err == nil && v > 0 {
+		opts.Workers = v
+	}
+	if v, err := strconv.Atoi(strings.TrimSpace(os.Getenv("SECRETVAULT_ARCHIVE_MAX_DEPTH"))); err == nil && v >= 0 {
+		opts.ArchiveMaxDepth = v
+	}
+	if v, err := strconv.ParseInt(strings.TrimSpace(os.Getenv("SECRETVAULT_ARCHIVE_MAX_BYTES")), 10, 64); err == nil && v > 0 {
+		opts.ArchiveMaxBytes = v
+	}
+	if v, err := strconv.ParseBool(strings.TrimSpace(os.Getenv("SECRETVAULT_SCAN_SOURCE"))); err == nil {
+		opts.ScanSource = v
+	}
+	if v, err := strconv.ParseBool(strings.TrimSpace(os.Getenv("SECRETVAULT_LOCK_SOURCE"))); err == nil {
+		opts.LockSource = v
+	}
+	return opts
+}
+
 func FindSensitiveFiles(roots []string) ([]string, error) {
-	result := make(map[string]struct{})
+	return FindSensitiveFilesWithOptions(roots, DefaultScanOptions())
+}
+
+func FindSensitiveFilesWithOptions(roots []string, opts ScanOptions) ([]string, error) {
+	detections, err := ScanSensitiveFiles(roots, opts)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]string, 0, len(detections))
+	for _, d := range detections {
+		if d.Reason.Source && !opts.LockSource {
+			continue
+		}
+		out = append(out, d.Path)
+	}
+	return out, nil
+}
+
+func ScanSensitiveFiles(roots []string, opts ScanOptions) ([]Detection, error) {
+	return ScanSensitiveFilesContext(context.Background(), roots, opts)
+}
 
+// ScanSensitiveFilesContext stops handing out work once ctx is done and
+// returns ctx.Err(); files already being read are finished first.
+func ScanSensitiveFilesContext(ctx context.Context, roots []string, opts ScanOptions) ([]Detection, error) {
+	jobs := make([]scanJob, 0, len(roots))
 	for _, root := range roots {
 		info, err := os.Stat(root)
 		if err != nil {
 			return nil, err
 		}
+		abs, err := filepath.Abs(root)
+		if err != nil {
+			return nil, err
+		}
 
 		if !info.IsDir() {
-			abs, err := filepath.Abs(root)
-			if err != nil {
-				return nil, err
-			}
-			ok, err := IsSensitiveFile(abs)
-			if err != nil {
-				return nil, err
-			}
-			if ok {
-				result[abs] = struct{}{}
-			}
+			jobs = append(jobs, scanJob{path: abs})
+			continue
+		}
+		if _, skip := IgnoredDirNames[strings.ToLower(info.Name())]; skip {
 			continue
 		}
 
-		err = filepath.WalkDir(root, func(path string, d fs.DirEntry, walkErr error) error {
-			if walkErr != nil {
-				return walkErr
-			}
-
-			name := strings.ToLower(d.Name())
-			if d.IsDir() {
-				if _, skip := IgnoredDirNames[name]; skip {
-					return filepath.SkipDir
-				}
-				return nil
-			}
-
-			if !d.Type().IsRegular() {
-				return nil
-			}
-			if strings.HasSuffix(strings.ToLower(path), EncryptedExt) {
-				return nil
-			}
-
-			abs, err := filepath.Abs(path)
+		var matcher *IgnoreMatcher
+		if !opts.NoIgnore {
+			matcher, err = NewIgnoreMatcher(abs)
 			if err != nil {
-				return err
-			}
-			ok, err := IsSensitiveFile(abs)
-			if err != nil {
-				return err
-			}
-			if ok {
-				result[abs] = struct{}{}
+				return nil, err
 			}
-			return nil
-		})
+		}
+		jobs = append(jobs, scanJob{path: abs, isDir: true, isRoot: true, matcher: matcher})
+	}
+
+	var index *scanIndex
+	if opts.IndexPath != "" && !opts.IncludeSuppressed {
+		var err error
+		index, err = loadScanIndex(opts.IndexPath, opts)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	return SortedKeys(result), nil
+	result, err := runParallelScan(ctx, jobs, opts, index)
+	if err != nil {
+		return nil, err
+	}
+
+	scanned := make([]string, 0, len(jobs))
+	for _, job := range jobs {
+		scanned = append(scanned, job.path)
+	}
+	if err := index.save(scanned); err != nil {
+		return nil, err
+	}
+
+	out := make([]Detection, 0, len(result))
+	for _, path := range sortedDetectionKeys(result) {
+		out = append(out, result[path])
+	}
+	return out, nil
+}
+
+func sortedDetectionKeys(in map[string]Detection) []string {
+	keys := make([]string, 0, len(in))
+	for k := range in {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
 }
 
 func FindEncryptedFiles(roots []string) ([]string, error) {
@@ -129,69 +238,118 @@ func FindEncryptedFiles(roots []string) ([]string, error) {
 	return SortedKeys(result), nil
 }
 
-func IsSensitiveFile(path string) (bool, error) {
-	base := strings.ToLower(filepath.Base(path))
-	if isGeneratedArtifact(base) {
-		return false, nil
+func (r SensitiveReason) Matched() bool {
+	return r.Rule != ""
+}
+
+func (r SensitiveReason) String() string {
+	if !r.Matched() {
+		return ""
 	}
-	if _, ok := SensitiveExactNames[base]; ok {
-		return true, nil
+	entry := ""
+	if r.Entry != "" {
+		entry = ", in " + r.Entry
 	}
-	if strings.HasPrefix(base, ".env.") {
-		return true, nil
+	if r.Line > 0 {
+		return fmt.Sprintf("%s, %s, line %d%s", r.Rule, r.Severity, r.Line, entry)
 	}
-	for _, suffix := range SensitiveSuffixes {
-		if strings.HasSuffix(base, suffix) {
-			return true, nil
+	return fmt.Sprintf("%s %s, %s%s", r.Rule, r.Pattern, r.Severity, entry)
+}
+
+func IsSensitiveFile(path string) (SensitiveReason, error) {
+	return detectSensitiveFile(path, DefaultScanOptions())
+}
+
+func detectSensitiveFile(path string, opts ScanOptions) (SensitiveReason, error) {
+	if valueLockCandidate(path) {
+		if f, err := os.Open(path); err == nil {
+			defer f.Close()
+			return DetectSensitiveBlob(path, f, opts)
 		}
 	}
-
-	if hasSensitiveDir(path) {
-		return true, nil
+	if reason, decided := detectBaselinedName(path, opts); decided {
+		return reason, nil
 	}
-
-	return looksSensitiveByContent(path)
+	if IsArchiveFile(path) {
+		return looksSensitiveInArchive(path, opts)
+	}
+	return looksSensitiveByContent(path, opts)
 }
 
-func hasSensitiveDir(path string) bool {
-	parts := strings.Split(strings.ToLower(filepath.Clean(path)), string(os.PathSeparator))
-	for _, part := range parts {
-		if _, ok := SensitiveDirNames[part]; ok {
-			return true
+func DetectSensitiveBlob(path string, r io.Reader, opts ScanOptions) (SensitiveReason, error) {
+	if valueLockCandidate(path) {
+		data, locked, err := readValueLocked(path, r, opts)
+		if err != nil {
+			return SensitiveReason{}, err
+		}
+		if locked {
+			return SensitiveReason{}, nil
 		}
+		if masked, ok := maskTfvarsTokens(path, data); ok {
+			return scanContent(path, io.MultiReader(bytes.NewReader(masked), r), opts)
+		}
+		r = io.MultiReader(bytes.NewReader(data), r)
 	}
-	return false
+	if reason, decided := detectBaselinedName(path, opts); decided {
+		return reason, nil
+	}
+	if IsArchiveFile(path) {
+		return scanArchiveContent(path, r, opts)
+	}
+	if !contentScannable(path, opts) {
+		return SensitiveReason{}, nil
+	}
+	return scanContent(path, r, opts)
 }
 
-func looksSensitiveByContent(path string) (bool, error) {
-	if !shouldScanFileContent(path) {
-		return false, nil
+func detectBaselinedName(path string, opts ScanOptions) (SensitiveReason, bool) {
+	reason, decided := detectSensitiveName(path)
+	if !reason.Matched() {
+		return reason, decided
 	}
+	reason.Fingerprint = opts.Baseline.fingerprint(path, reason.Rule, "")
+	if opts.Baseline.Contains(reason.Fingerprint) {
+		return SensitiveReason{}, false
+	}
+	return reason, true
+}
 
-	info, err := os.Stat(path)
-	if err != nil {
-		return false, err
+func detectSensitiveName(path string) (SensitiveReason, bool) {
+	base := strings.ToLower(filepath.Base(path))
+	if isGeneratedArtifact(base) {
+		return SensitiveReason{}, true
 	}
-	if info.Size() == 0 || info.Size() > 1<<20 {
-		return false, nil
+	if _, ok := SensitiveExactNames[base]; ok {
+		return SensitiveReason{Rule: RuleExactName, Pattern: base, Severity: SeverityHigh}, true
 	}
-
-	f, err := os.Open(path)
-	if err != nil {
-		return false, err
+	if strings.HasPrefix(base, ".env.") && !isDotenvCompanion(base) {
+		return SensitiveReason{Rule: RuleEnvFile, Pattern: ".env.*", Severity: SeverityHigh}, true
 	}
-	defer f.Close()
-
-	buf := make([]byte, 4096)
-	n, err := f.Read(buf)
-	if err != nil && !errors.Is(err, io.EOF) {
-		return false, err
+	for _, suffix := range SensitiveSuffixes {
+		if strings.HasSuffix(base, suffix) {
+			return SensitiveReason{Rule: RuleSuffix, Pattern: "*" + suffix, Severity: SeverityHigh}, true
+		}
 	}
-	if n == 0 {
-		return false, nil
+
+	if dir, ok := sensitiveDirOf(path); ok {
+		return SensitiveReason{Rule: RuleSensitiveDir, Pattern: dir + "/", Severity: SeverityMedium}, true
 	}
+	return SensitiveReason{}, false
+}
 
-	return SecretContentPattern.Match(buf[:n]), nil
+func hasSensitiveDir(path string) bool {
+	_, ok := sensitiveDirOf(path)
+	return ok
+}
+
+func sensitiveDirOf(path string) (string, bool) {
+	parts := strings.Split(strings.ToLower(filepath.Clean(path)), string(os.PathSeparator))
+	for _, part := range parts {
+		if _, ok := SensitiveDirNames[part]; ok {
+			return part, true
+		}
+	}
+	return "", false
 }
 
 func isGeneratedArtifact(base string) bool {
@@ -206,18 +364,3 @@ func isGeneratedArtifact(base string) bool {
 	}
 	return false
 }
-
-func shouldScanFileContent(path string) bool {
-	base := strings.ToLower(filepath.Base(path))
-	if strings.HasPrefix(base, ".env") {
-		return true
-	}
-
-	ext := strings.ToLower(filepath.Ext(base))
-	switch ext {
-	case ".txt", ".json", ".yaml", ".yml", ".toml", ".ini", ".conf", ".config", ".cfg", ".properties":
-		return true
-	default:
-		return false
-	}
-}
@@ -5,13 +5,17 @@ import (
 	"encoding/hex"
 	"os"
 	"path/filepath"
-	"regexp"
 	"sort"
 	"strings"
 )
 
 const (
 	EncryptedExt = ".svault"
+
+	LockModeFile             = "file"
+	LockModeDotenvValues     = "dotenv-values"
+	LockModeStructuredValues = "structured-values"
+	LockModeTfvarsValues     = "tfvars-values"
 )
 
 var (
@@ -58,6 +62,69 @@ var (
 		".gnupg":      {},
 	}
 
+	ContentScanExtensions = map[string]struct{}{
+		".txt":        {},
+		".json":       {},
+		".jsonc":      {},
+		".json5":      {},
+		".yaml":       {},
+		".yml":        {},
+		".toml":       {},
+		".ini":        {},
+		".conf":       {},
+		".config":     {},
+		".cfg":        {},
+		".cnf":        {},
+		".properties": {},
+		".xml":        {},
+		".plist":      {},
+		".env":        {},
+		".secret":     {},
+		".secrets":    {},
+		".creds":      {},
+	}
+
+	SourceScanExtensions = map[string]struct{}{
+		".go":   {},
+		".py":   {},
+		".rb":   {},
+		".js":   {},
+		".jsx":  {},
+		".mjs":  {},
+		".cjs":  {},
+		".ts":   {},
+		".tsx":  {},
+		".java": {},
+		".kt":   {},
+		".cs":   {},
+		".php":  {},
+		".rs":   {},
+		".sh":   {},
+		".tf":   {},
+	}
+
+	ContentScanNames = map[string]struct{}{
+		".netrc":           {},
+		".pgpass":          {},
+		".git-credentials": {},
+		".dockercfg":       {},
+		".htpasswd":        {},
+		".s3cfg":           {},
+		".boto":            {},
+		".my.cnf":          {},
+	}
+
+	ContentScanSkipNames = map[string]struct{}{
+		"package-lock.json":   {},
+		"npm-shrinkwrap.json": {},
+		"yarn.lock":           {},
+		"pnpm-lock.yaml":      {},
+		"composer.lock":       {},
+		"packages.lock.json":  {},
+		"flake.lock":          {},
+		BaselineFileName:      {},
+	}
+
 	IgnoredDirNames = map[string]struct{}{
 		".git":         {},
 		".terraform":   {},
@@ -73,8 +140,6 @@ var (
 		".vscode":      {},
 		".ai-sessions": {},
 	}
-
-	SecretContentPattern = regexp.MustCompile(`(?i)(api[_-]?key|token|password|private[_-]?key|secret[_-]?(key|token|value))\s*[:=]|aws_secret_access_key|-----BEGIN (RSA|EC|OPENSSH|PGP) PRIVATE KEY-----`)
 )
 
 type ProjectContext struct {
@@ -92,21 +157,23 @@ type VaultManifest struct {
 }
 
 type VaultEntry struct {
-	FileID               string `json:"file_id"`
-	AbsolutePath         string `json:"absolute_path"`
-	RelativePath         string `json:"relative_path,omitempty"`
-	Directory            string `json:"directory"`
-	Filename             string `json:"filename"`
-	VaultFile            string `json:"vault_file"`
-	ProjectEncryptedFile string `json:"project_encrypted_file"`
-	LockedAt             string `json:"locked_at"`
-	LastRestoredAt       string `json:"last_restored_at,omitempty"`
-	OriginalMode         uint32 `json:"original_mode"`
-	OnePasswordVault     string `json:"onepassword_vault,omitempty"`
-	OnePasswordDocument  string `json:"onepassword_document,omitempty"`
-	OnePasswordTitle     string `json:"onepassword_title,omitempty"`
-	ChecksumSHA256       string `json:"checksum_sha256,omitempty"`
-	AbsorbedAt           string `json:"absorbed_at,omitempty"`
+	FileID               string   `json:"file_id"`
+	AbsolutePath         string   `json:"absolute_path"`
+	RelativePath         string   `json:"relative_path,omitempty"`
+	Directory            string   `json:"directory"`
+	Filename             string   `json:"filename"`
+	VaultFile            string   `json:"vault_file"`
+	ProjectEncryptedFile string   `json:"project_encrypted_file"`
+	LockedAt             string   `json:"locked_at"`
+	LastRestoredAt       string   `json:"last_restored_at,omitempty"`
+	OriginalMode         uint32   `json:"original_mode"`
+	OnePasswordVault     string   `json:"onepassword_vault,omitempty"`
+	OnePasswordDocument  string   `json:"onepassword_document,omitempty"`
+	OnePasswordTitle     string   `json:"onepassword_title,omitempty"`
+	ChecksumSHA256       string   `json:"checksum_sha256,omitempty"`
+	AbsorbedAt           string   `json:"absorbed_at,omitempty"`
+	LockMode             string   `json:"lock_mode,omitempty"`
+	LockVariables        []string `json:"lock_variables,omitempty"`
 }
 
 func LoadProjectContext() (ProjectContext, error) {
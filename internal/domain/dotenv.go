@@ -0,0 +1,300 @@
+package domain
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+type DotenvEntry struct {
+	Key    string
+	Value  string
+	Raw    string
+	Quote  byte
+	Export bool
+	Line   int
+	start  int
+	end    int
+}
+
+type DotenvFile struct {
+	data    string
+	Entries []DotenvEntry
+}
+
+type DotenvSyntaxError struct {
+	Line int
+	Msg  string
+}
+
+func (e *DotenvSyntaxError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Msg)
+}
+
+func IsDotenvFile(path string) bool {
+	base := strings.ToLower(filepath.Base(path))
+	if base == ".env" || strings.HasPrefix(base, ".env.") {
+		return !strings.HasSuffix(base, EncryptedExt)
+	}
+	return strings.HasSuffix(base, ".env")
+}
+
+func ParseDotenv(data []byte) (*DotenvFile, error) {
+	p := dotenvParser{src: string(data), line: 1}
+	file := &DotenvFile{data: p.src}
+	for !p.eof() {
+		entry, ok, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			file.Entries = append(file.Entries, entry)
+		}
+	}
+	return file, nil
+}
+
+func (f *DotenvFile) Lookup(key string) (DotenvEntry, bool) {
+	for i := len(f.Entries) - 1; i >= 0; i-- {
+		if f.Entries[i].Key == key {
+			return f.Entries[i], true
+		}
+	}
+	return DotenvEntry{}, false
+}
+
+func (f *DotenvFile) Values() map[string]string {
+	out := make(map[string]string, len(f.Entries))
+	for _, entry := range f.Entries {
+		out[entry.Key] = entry.Value
+	}
+	return out
+}
+
+func (f *DotenvFile) Bytes() []byte {
+	return []byte(f.data)
+}
+
+func (f *DotenvFile) ReplaceRaw(replace func(DotenvEntry) (string, bool)) []byte {
+	var b strings.Builder
+	last := 0
+	for _, entry := range f.Entries {
+		raw, ok := replace(entry)
+		if !ok {
+			continue
+		}
+		b.WriteString(f.data[last:entry.start])
+		b.WriteString(raw)
+		last = entry.end
+	}
+	b.WriteString(f.data[last:])
+	return []byte(b.String())
+}
+
+type dotenvParser struct {
+	src  string
+	pos  int
+	line int
+}
+
+func (p *dotenvParser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *dotenvParser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+func (p *dotenvParser) advance() byte {
+	c := p.src[p.pos]
+	p.pos++
+	if c == '\n' {
+		p.line++
+	}
+	return c
+}
+
+func (p *dotenvParser) skipInlineSpace() {
+	for !p.eof() && (p.peek() == ' ' || p.peek() == '\t') {
+		p.pos++
+	}
+}
+
+func (p *dotenvParser) skipLine() {
+	for !p.eof() {
+		if p.advance() == '\n' {
+			return
+		}
+	}
+}
+
+func (p *dotenvParser) restOfLineIsComment() bool {
+	p.skipInlineSpace()
+	if p.eof() || p.peek() == '\n' || p.peek() == '\r' || p.peek() == '#' {
+		p.skipLine()
+		return true
+	}
+	return false
+}
+
+func (p *dotenvParser) next() (DotenvEntry, bool, error) {
+	p.skipInlineSpace()
+	if p.eof() {
+		return DotenvEntry{}, false, nil
+	}
+	if c := p.peek(); c == '\n' || c == '\r' || c == '#' {
+		p.skipLine()
+		return DotenvEntry{}, false, nil
+	}
+
+	entry := DotenvEntry{Line: p.line}
+	if strings.HasPrefix(p.src[p.pos:], "export ") || strings.HasPrefix(p.src[p.pos:], "export\t") {
+		entry.Export = true
+		p.pos += len("export")
+		p.skipInlineSpace()
+	}
+
+	keyStart := p.pos
+	for !p.eof() && isDotenvKeyChar(p.peek(), p.pos == keyStart) {
+		p.pos++
+	}
+	entry.Key = p.src[keyStart:p.pos]
+	if entry.Key == "" {
+		line := p.line
+		p.skipLine()
+		return DotenvEntry{}, false, &DotenvSyntaxError{Line: line, Msg: "expected variable name"}
+	}
+	p.skipInlineSpace()
+	if p.peek() != '=' {
+		line := p.line
+		p.skipLine()
+		return DotenvEntry{}, false, &DotenvSyntaxError{Line: line, Msg: fmt.Sprintf("expected '=' after %s", entry.Key)}
+	}
+	p.pos++
+	p.skipInlineSpace()
+
+	entry.start = p.pos
+	switch q := p.peek(); q {
+	case '"', '\'', '`':
+		entry.Quote = q
+		value, err := p.quoted(q)
+		if err != nil {
+			return DotenvEntry{}, false, err
+		}
+		entry.Value = value
+		entry.end = p.pos
+		entry.Raw = p.src[entry.start:entry.end]
+		if !p.restOfLineIsComment() {
+			return DotenvEntry{}, false, &DotenvSyntaxError{Line: p.line, Msg: fmt.Sprintf("unexpected characters after quoted value of %s", entry.Key)}
+		}
+	default:
+		end := p.pos
+		for !p.eof() && p.peek() != '\n' {
+			if p.peek() == '#' && end > entry.start && (p.src[p.pos-1] == ' ' || p.src[p.pos-1] == '\t') {
+				break
+			}
+			p.pos++
+			end = p.pos
+		}
+		entry.end = entry.start + len(strings.TrimRight(p.src[entry.start:end], " \t\r"))
+		entry.Raw = p.src[entry.start:entry.end]
+		entry.Value = entry.Raw
+		p.skipLine()
+	}
+	return entry, true, nil
+}
+
+func (p *dotenvParser) quoted(q byte) (string, error) {
+	startLine := p.line
+	p.pos++
+	var b strings.Builder
+	for !p.eof() {
+		c := p.advance()
+		switch {
+		case c == q:
+			return b.String(), nil
+		case c == '\\' && q == '"' && !p.eof():
+			e := p.advance()
+			switch e {
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case '"', '\\', '$':
+				b.WriteByte(e)
+			default:
+				b.WriteByte('\\')
+				b.WriteByte(e)
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", &DotenvSyntaxError{Line: startLine, Msg: fmt.Sprintf("unterminated %c-quoted value", q)}
+}
+
+func isDotenvKeyChar(c byte, first bool) bool {
+	switch {
+	case c == '_' || (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z'):
+		return true
+	case first:
+		return false
+	default:
+		return (c >= '0' && c <= '9') || c == '.' || c == '-'
+	}
+}
+
+var dotenvEscaper = strings.NewReplacer("\\", `\\`, "\"", `\"`, "$", `\$`, "\n", `\n`, "\r", `\r`)
+
+func ValidDotenvKey(key string) bool {
+	for i := 0; i < len(key); i++ {
+		if !isDotenvKeyChar(key[i], i == 0) {
+			return false
+		}
+	}
+	return key != ""
+}
+
+func (f *DotenvFile) Set(key, value string) []byte {
+	current, ok := f.Lookup(key)
+	if !ok {
+		data := f.data
+		if data != "" && !strings.HasSuffix(data, "\n") {
+			data += "\n"
+		}
+		return []byte(data + key + "=" + QuoteDotenvValue(value) + "\n")
+	}
+
+	raw := QuoteDotenvValue(value)
+	switch {
+	case current.Quote == '"':
+		raw = `"` + dotenvEscaper.Replace(value) + `"`
+	case current.Quote == '\'' && !strings.ContainsAny(value, "'\n\r"):
+		raw = "'" + value + "'"
+	}
+	return f.ReplaceRaw(func(entry DotenvEntry) (string, bool) {
+		return raw, entry.start == current.start
+	})
+}
+
+func QuoteDotenvValue(value string) string {
+	if value == "" {
+		return ""
+	}
+	plain := true
+	for _, c := range value {
+		if c == ' ' || c == '\t' || c == '#' || c == '"' || c == '\'' || c == '`' || c == '\\' || c == '\n' || c == '\r' || c == '$' {
+			plain = false
+			break
+		}
+	}
+	if plain {
+		return value
+	}
+	return `"` + dotenvEscaper.Replace(value) + `"`
+}
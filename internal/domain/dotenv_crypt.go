@@ -0,0 +1,151 @@
+package domain
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const ValueTokenPrefix = "svault:v1:"
+
+func IsValueToken(raw string) bool {
+	return strings.HasPrefix(raw, ValueTokenPrefix)
+}
+
+func EncryptDotenvValue(key []byte, name, raw string) (string, error) {
+	return sealValueToken(key, name, raw)
+}
+
+func DecryptDotenvValue(key []byte, name, token string) (string, error) {
+	return openValueToken(key, name, token)
+}
+
+func sealValueToken(key []byte, name, raw string) (string, error) {
+	aead, err := newValueAEAD(key)
+	if err != nil {
+		return "", err
+	}
+	// Deterministic nonce: re-locking an unchanged value yields the same token.
+	nonceKey := sha256.Sum256(append([]byte(ValueTokenPrefix+"nonce\x00"), key...))
+	mac := hmac.New(sha256.New, nonceKey[:])
+	mac.Write([]byte(ValueTokenPrefix + name + "\x00" + raw))
+	nonce := mac.Sum(nil)[:aead.NonceSize()]
+
+	sealed := aead.Seal(append([]byte(nil), nonce...), nonce, []byte(raw), []byte(ValueTokenPrefix+name))
+	return ValueTokenPrefix + base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+func openValueToken(key []byte, name, token string) (string, error) {
+	if !IsValueToken(token) {
+		return "", fmt.Errorf("%s is not an encrypted value", name)
+	}
+	sealed, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(token, ValueTokenPrefix))
+	if err != nil {
+		return "", fmt.Errorf("decode %s: %w", name, err)
+	}
+	aead, err := newValueAEAD(key)
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return "", fmt.Errorf("decode %s: %w", name, errors.New("token too short"))
+	}
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], []byte(ValueTokenPrefix+name))
+	if err != nil {
+		return "", fmt.Errorf("decrypt %s: %w", name, err)
+	}
+	return string(plaintext), nil
+}
+
+func EncryptDotenvValues(data, key []byte) ([]byte, int, error) {
+	file, err := ParseDotenv(data)
+	if err != nil {
+		return nil, 0, err
+	}
+	count := 0
+	var encErr error
+	out := file.ReplaceRaw(func(entry DotenvEntry) (string, bool) {
+		if encErr != nil || entry.Raw == "" || IsValueToken(entry.Raw) {
+			return "", false
+		}
+		token, err := EncryptDotenvValue(key, entry.Key, entry.Raw)
+		if err != nil {
+			encErr = err
+			return "", false
+		}
+		count++
+		return token, true
+	})
+	if encErr != nil {
+		return nil, 0, encErr
+	}
+	return out, count, nil
+}
+
+func DecryptDotenvValues(data, key []byte) ([]byte, int, error) {
+	file, err := ParseDotenv(data)
+	if err != nil {
+		return nil, 0, err
+	}
+	count := 0
+	var decErr error
+	out := file.ReplaceRaw(func(entry DotenvEntry) (string, bool) {
+		if decErr != nil || !IsValueToken(entry.Raw) {
+			return "", false
+		}
+		raw, err := DecryptDotenvValue(key, entry.Key, entry.Raw)
+		if err != nil {
+			decErr = err
+			return "", false
+		}
+		count++
+		return raw, true
+	})
+	if decErr != nil {
+		return nil, 0, decErr
+	}
+	return out, count, nil
+}
+
+func HasDotenvTokens(data []byte) bool {
+	file, err := ParseDotenv(data)
+	if err != nil {
+		return false
+	}
+	for _, entry := range file.Entries {
+		if IsValueToken(entry.Raw) {
+			return true
+		}
+	}
+	return false
+}
+
+func IsDotenvFullyEncrypted(data []byte) bool {
+	file, err := ParseDotenv(data)
+	if err != nil {
+		return false
+	}
+	encrypted := 0
+	for _, entry := range file.Entries {
+		switch {
+		case IsValueToken(entry.Raw):
+			encrypted++
+		case entry.Raw != "":
+			return false
+		}
+	}
+	return encrypted > 0
+}
+
+func newValueAEAD(key []byte) (cipher.AEAD, error) {
+	blk, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(blk)
+}
@@ -0,0 +1,148 @@
+package domain
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+var DotenvExampleNames = map[string]struct{}{
+	".env.example":  {},
+	".env.sample":   {},
+	".env.template": {},
+	".env.dist":     {},
+}
+
+type DotenvExampleSource struct {
+	Path string
+	File *DotenvFile
+}
+
+type MissingDotenvKey struct {
+	Key    string
+	Source string
+}
+
+func IsDotenvExample(path string) bool {
+	_, ok := DotenvExampleNames[strings.ToLower(filepath.Base(path))]
+	return ok
+}
+
+// isDotenvPlaceholder reports an assignment left empty, as example files do for
+// every value they document.
+func isDotenvPlaceholder(line string) bool {
+	key, value, ok := strings.Cut(strings.TrimSpace(line), "=")
+	if !ok || !ValidDotenvKey(strings.TrimSpace(strings.TrimPrefix(key, "export "))) {
+		return false
+	}
+	value = strings.TrimSpace(value)
+	if strings.HasPrefix(value, `""`) || strings.HasPrefix(value, "''") {
+		value = strings.TrimSpace(value[2:])
+	}
+	return value == "" || strings.HasPrefix(value, "#")
+}
+
+func DotenvExample(sources []DotenvExampleSource) []byte {
+	var b strings.Builder
+	seen := map[string]bool{}
+	for _, source := range sources {
+		data := source.File.data
+		header, contributed := len(sources) > 1, false
+		prev, emitted := 0, false
+		for _, entry := range source.File.Entries {
+			lineStart := strings.LastIndexByte(data[:entry.start], '\n') + 1
+			inline, gap := splitDotenvGap(data[prev:lineStart], prev > 0)
+			if emitted {
+				writeDotenvLineEnd(&b, inline)
+			}
+			prev, emitted = entry.end, !seen[entry.Key]
+			if !emitted {
+				continue
+			}
+			seen[entry.Key], contributed = true, true
+			if header {
+				if b.Len() > 0 {
+					b.WriteString("\n")
+				}
+				b.WriteString("# " + source.Path + "\n")
+				header = false
+			}
+			writeDotenvComments(&b, gap)
+			if entry.Export {
+				b.WriteString("export ")
+			}
+			b.WriteString(entry.Key + "=")
+		}
+		if contributed {
+			inline, footer := splitDotenvGap(data[prev:], true)
+			if emitted {
+				writeDotenvLineEnd(&b, inline)
+			}
+			writeDotenvComments(&b, footer)
+		}
+	}
+	return []byte(strings.TrimRight(b.String(), "\n") + "\n")
+}
+
+func MissingDotenvKeys(example *DotenvFile, sources []DotenvExampleSource) []MissingDotenvKey {
+	var missing []MissingDotenvKey
+	seen := map[string]bool{}
+	for _, source := range sources {
+		for _, entry := range source.File.Entries {
+			if seen[entry.Key] {
+				continue
+			}
+			seen[entry.Key] = true
+			if _, ok := example.Lookup(entry.Key); !ok {
+				missing = append(missing, MissingDotenvKey{Key: entry.Key, Source: source.Path})
+			}
+		}
+	}
+	return missing
+}
+
+// splitDotenvGap separates the trailing comment of the previous assignment
+// from the comment and blank lines that precede the next one.
+func splitDotenvGap(gap string, afterEntry bool) (string, []string) {
+	inline := ""
+	if afterEntry {
+		rest, tail, _ := strings.Cut(gap, "\n")
+		if rest = strings.TrimSpace(rest); strings.HasPrefix(rest, "#") {
+			inline = rest
+		}
+		gap = tail
+	}
+	gap = strings.TrimSuffix(gap, "\n")
+	if gap == "" {
+		return inline, nil
+	}
+	var lines []string
+	for _, line := range strings.Split(gap, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+	return inline, lines
+}
+
+func writeDotenvLineEnd(b *strings.Builder, inline string) {
+	if inline != "" {
+		b.WriteString(" " + inline)
+	}
+	b.WriteString("\n")
+}
+
+func writeDotenvComments(b *strings.Builder, lines []string) {
+	blank := b.Len() == 0 || strings.HasSuffix(b.String(), "\n\n")
+	for _, line := range lines {
+		if line == "" {
+			if !blank {
+				b.WriteString("\n")
+			}
+			blank = true
+			continue
+		}
+		b.WriteString(line + "\n")
+		blank = false
+	}
+}
@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDotenvExampleKeepsCommentsAndDropsValues(t *testing.T) {
+	base := mustParseDotenv(t, "# Database\nDB_URL=\"postgres://u:p@db/app\" # primary\n\n\n# API\nexport API_KEY=sk-live\nDB_URL=override\n# trailing note\n")
+	prod := mustParseDotenv(t, "# Production only\nSENTRY_DSN='https://x@sentry'\nAPI_KEY=sk-prod # rotated\n")
+
+	got := string(DotenvExample([]DotenvExampleSource{{Path: ".env", File: base}, {Path: ".env.production", File: prod}}))
+	want := "# .env\n# Database\nDB_URL= # primary\n\n# API\nexport API_KEY=\n# trailing note\n" +
+		"\n# .env.production\n# Production only\nSENTRY_DSN=\n"
+	if got != want {
+		t.Fatalf("unexpected example:\n%s\nwant:\n%s", got, want)
+	}
+
+	got = string(DotenvExample([]DotenvExampleSource{{Path: ".env", File: mustParseDotenv(t, "A=1\nB=2")}}))
+	if got != "A=\nB=\n" {
+		t.Fatalf("unexpected single-file example %q", got)
+	}
+}
+
+func TestMissingDotenvKeys(t *testing.T) {
+	example := mustParseDotenv(t, "DB_URL=\n# API_KEY=\n")
+	sources := []DotenvExampleSource{
+		{Path: ".env", File: mustParseDotenv(t, "DB_URL=x\nAPI_KEY=y\n")},
+		{Path: ".env.production", File: mustParseDotenv(t, "API_KEY=z\nSENTRY_DSN=s\n")},
+	}
+	want := []MissingDotenvKey{{Key: "API_KEY", Source: ".env"}, {Key: "SENTRY_DSN", Source: ".env.production"}}
+	if got := MissingDotenvKeys(example, sources); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+	if !IsDotenvExample("app/.env.example") || IsDotenvExample(".env.production") {
+		t.Fatal("unexpected example name detection")
+	}
+}
+
+func mustParseDotenv(t *testing.T, src string) *DotenvFile {
+	t.Helper()
+	file, err := ParseDotenv([]byte(src))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	return file
+}
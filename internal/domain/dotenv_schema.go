@@ -0,0 +1,193 @@
+package domain
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const DotenvSchemaFile = ".env.schema"
+
+const (
+	DotenvMissing   = "missing"
+	DotenvExtra     = "extra"
+	DotenvMalformed = "malformed"
+)
+
+var dotenvSchemaTypes = map[string]struct{}{
+	"string": {},
+	"int":    {},
+	"number": {},
+	"bool":   {},
+	"url":    {},
+}
+
+type DotenvSchema struct {
+	Rules []DotenvSchemaRule
+}
+
+type DotenvSchemaRule struct {
+	Key      string
+	Required bool
+	Type     string
+	Enum     []string
+	Pattern  *regexp.Regexp
+	Line     int
+}
+
+type DotenvProblem struct {
+	Kind   string
+	Key    string
+	Line   int
+	Detail string
+}
+
+func IsDotenvSchema(path string) bool {
+	return strings.EqualFold(filepath.Base(path), DotenvSchemaFile)
+}
+
+func isDotenvCompanion(path string) bool {
+	return IsDotenvExample(path) || IsDotenvSchema(path)
+}
+
+// dotenvSchemaRules returns the rule list of a schema assignment so the content
+// scan checks it without the key, which names a secret by design.
+func dotenvSchemaRules(line string) (string, bool) {
+	file, err := ParseDotenv([]byte(line))
+	if err != nil || len(file.Entries) != 1 {
+		return "", false
+	}
+	if _, err := parseDotenvSchemaRule(file.Entries[0]); err != nil {
+		return "", false
+	}
+	return file.Entries[0].Value, true
+}
+
+// ParseDotenvSchema reads a .env.schema file: dotenv syntax where each value
+// is a comma-separated rule list such as "required,int" or
+// "optional,pattern:^sk-[a-z0-9]+$". pattern consumes the rest of the value.
+func ParseDotenvSchema(data []byte) (*DotenvSchema, error) {
+	file, err := ParseDotenv(data)
+	if err != nil {
+		return nil, err
+	}
+	schema := &DotenvSchema{}
+	seen := map[string]bool{}
+	for _, entry := range file.Entries {
+		if seen[entry.Key] {
+			return nil, &DotenvSyntaxError{Line: entry.Line, Msg: fmt.Sprintf("%s is declared twice", entry.Key)}
+		}
+		seen[entry.Key] = true
+		rule, err := parseDotenvSchemaRule(entry)
+		if err != nil {
+			return nil, err
+		}
+		schema.Rules = append(schema.Rules, rule)
+	}
+	return schema, nil
+}
+
+func parseDotenvSchemaRule(entry DotenvEntry) (DotenvSchemaRule, error) {
+	rule := DotenvSchemaRule{Key: entry.Key, Type: "string", Line: entry.Line}
+	fail := func(format string, args ...any) (DotenvSchemaRule, error) {
+		return DotenvSchemaRule{}, &DotenvSyntaxError{Line: entry.Line, Msg: entry.Key + ": " + fmt.Sprintf(format, args...)}
+	}
+	rest := strings.TrimSpace(entry.Value)
+	for rest != "" {
+		token := rest
+		if strings.HasPrefix(token, "pattern:") {
+			rest = ""
+		} else {
+			token, rest, _ = strings.Cut(rest, ",")
+			token, rest = strings.TrimSpace(token), strings.TrimSpace(rest)
+		}
+		name, arg, _ := strings.Cut(token, ":")
+		switch name {
+		case "required":
+			rule.Required = true
+		case "optional":
+			rule.Required = false
+		case "enum":
+			if arg == "" {
+				return fail("enum needs values, e.g. enum:debug|info")
+			}
+			rule.Enum = strings.Split(arg, "|")
+		case "pattern":
+			re, err := regexp.Compile("^(?:" + arg + ")$")
+			if err != nil {
+				return fail("invalid pattern: %v", err)
+			}
+			rule.Pattern = re
+		default:
+			if _, ok := dotenvSchemaTypes[name]; !ok || arg != "" {
+				return fail("unknown rule %q", token)
+			}
+			rule.Type = name
+		}
+	}
+	return rule, nil
+}
+
+func (s *DotenvSchema) Validate(file *DotenvFile) []DotenvProblem {
+	var problems []DotenvProblem
+	declared := map[string]bool{}
+	for _, rule := range s.Rules {
+		declared[rule.Key] = true
+		entry, ok := file.Lookup(rule.Key)
+		if !ok || entry.Value == "" {
+			if rule.Required {
+				problems = append(problems, DotenvProblem{Kind: DotenvMissing, Key: rule.Key, Line: entry.Line})
+			}
+			continue
+		}
+		if detail := rule.check(entry.Value); detail != "" {
+			problems = append(problems, DotenvProblem{Kind: DotenvMalformed, Key: rule.Key, Line: entry.Line, Detail: detail})
+		}
+	}
+	reported := map[string]bool{}
+	for _, entry := range file.Entries {
+		if !declared[entry.Key] && !reported[entry.Key] {
+			reported[entry.Key] = true
+			problems = append(problems, DotenvProblem{Kind: DotenvExtra, Key: entry.Key, Line: entry.Line})
+		}
+	}
+	return problems
+}
+
+// check never echoes the value, since it is usually a secret.
+func (r DotenvSchemaRule) check(value string) string {
+	switch r.Type {
+	case "int":
+		if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+			return "expected int"
+		}
+	case "number":
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return "expected number"
+		}
+	case "bool":
+		if _, err := strconv.ParseBool(value); err != nil {
+			return "expected bool"
+		}
+	case "url":
+		if u, err := url.Parse(value); err != nil || u.Scheme == "" || u.Host == "" {
+			return "expected url"
+		}
+	}
+	if len(r.Enum) > 0 {
+		found := false
+		for _, allowed := range r.Enum {
+			found = found || value == allowed
+		}
+		if !found {
+			return "expected one of " + strings.Join(r.Enum, "|")
+		}
+	}
+	if r.Pattern != nil && !r.Pattern.MatchString(value) {
+		return "does not match pattern " + strings.TrimSuffix(strings.TrimPrefix(r.Pattern.String(), "^(?:"), ")$")
+	}
+	return ""
+}
@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestDotenvSchemaValidate(t *testing.T) {
+	schema, err := ParseDotenvSchema([]byte("# required settings\nDATABASE_URL=required,url\nPORT=required,int\nLOG_LEVEL=enum:debug|info|warn\nAPI_KEY='required,pattern:sk-[a-z0-9]{4,}'\nDEBUG=bool\n"))
+	if err != nil {
+		t.Fatalf("parse schema: %v", err)
+	}
+
+	file := mustParseDotenv(t, "DATABASE_URL=postgres://u:p@db/app\nPORT=80a\nLOG_LEVEL=trace\nAPI_KEY=sk-abc1\nDEBUG=\nSTRAY=1\n")
+	want := []DotenvProblem{
+		{Kind: DotenvMalformed, Key: "PORT", Line: 2, Detail: "expected int"},
+		{Kind: DotenvMalformed, Key: "LOG_LEVEL", Line: 3, Detail: "expected one of debug|info|warn"},
+		{Kind: DotenvExtra, Key: "STRAY", Line: 6},
+	}
+	if got := schema.Validate(file); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+
+	file = mustParseDotenv(t, "PORT=8080\nAPI_KEY=pk-1234\nDATABASE_URL=\n")
+	want = []DotenvProblem{
+		{Kind: DotenvMissing, Key: "DATABASE_URL", Line: 3},
+		{Kind: DotenvMalformed, Key: "API_KEY", Line: 2, Detail: "does not match pattern sk-[a-z0-9]{4,}"},
+	}
+	if got := schema.Validate(file); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+}
+
+func TestParseDotenvSchemaRejectsUnknownRules(t *testing.T) {
+	for _, src := range []string{"A=required,uuid\n", "A=enum:\n", "A=pattern:(\n", "A=int\nA=bool\n"} {
+		var syntaxErr *DotenvSyntaxError
+		if _, err := ParseDotenvSchema([]byte(src)); !errors.As(err, &syntaxErr) {
+			t.Fatalf("%q: expected syntax error, got %v", src, err)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package domain
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestParseDotenvQuotingAndComments(t *testing.T) {
+	src := "# app config\n" +
+		"export API_KEY=abc123 # trailing comment\n" +
+		"URL=http://x/#frag\n" +
+		"SINGLE='raw \\n value'\n" +
+		"DOUBLE=\"line1\\nline2 \\\"q\\\"\"\n" +
+		"MULTI=\"first\nsecond\"\n" +
+		"EMPTY=\n"
+	file, err := ParseDotenv([]byte(src))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	want := map[string]string{
+		"API_KEY": "abc123",
+		"URL":     "http://x/#frag",
+		"SINGLE":  "raw \\n value",
+		"DOUBLE":  "line1\nline2 \"q\"",
+		"MULTI":   "first\nsecond",
+		"EMPTY":   "",
+	}
+	got := file.Values()
+	for k, v := range want {
+		if got[k] != v {
+			t.Fatalf("%s: expected %q, got %q", k, v, got[k])
+		}
+	}
+	entry, ok := file.Lookup("API_KEY")
+	if !ok || !entry.Export || entry.Line != 2 {
+		t.Fatalf("unexpected API_KEY entry: %+v", entry)
+	}
+	if string(file.Bytes()) != src {
+		t.Fatal("expected Bytes to round-trip the source")
+	}
+
+	if _, err := ParseDotenv([]byte("BROKEN=\"never closed\n")); err == nil {
+		t.Fatal("expected unterminated quote to fail")
+	}
+}
+
+func TestDotenvValueEncryptionPreservesLayout(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, 32)
+	src := []byte("# keep me\nexport DB_PASSWORD=\"s3cr3t value\"\n\nTOKEN=abc # note\nEMPTY=\n")
+
+	locked, n, err := EncryptDotenvValues(src, key)
+	if err != nil {
+		t.Fatalf("encrypt: %v", err)
+	}
+	if n != 2 {
+		t.Fatalf("expected 2 encrypted values, got %d", n)
+	}
+	text := string(locked)
+	if strings.Contains(text, "s3cr3t") || strings.Contains(text, "=abc") {
+		t.Fatalf("plaintext leaked: %s", text)
+	}
+	if !strings.HasPrefix(text, "# keep me\nexport DB_PASSWORD="+ValueTokenPrefix) || !strings.Contains(text, " # note\nEMPTY=\n") {
+		t.Fatalf("layout not preserved: %s", text)
+	}
+	if !IsDotenvFullyEncrypted(locked) {
+		t.Fatal("expected file to be fully encrypted")
+	}
+
+	again, n, err := EncryptDotenvValues(locked, key)
+	if err != nil || n != 0 || !bytes.Equal(again, locked) {
+		t.Fatalf("expected re-lock to be a no-op, got n=%d err=%v", n, err)
+	}
+
+	plain, n, err := DecryptDotenvValues(locked, key)
+	if err != nil {
+		t.Fatalf("decrypt: %v", err)
+	}
+	if n != 2 || !bytes.Equal(plain, src) {
+		t.Fatalf("expected original content, got %q", plain)
+	}
+
+	if _, _, err := DecryptDotenvValues(locked, bytes.Repeat([]byte{8}, 32)); err == nil {
+		t.Fatal("expected wrong key to fail")
+	}
+}
+
+func TestDotenvSetPreservesFormatting(t *testing.T) {
+	src := "# api\nexport API_KEY=\"old\" # rotate monthly\nNAME='svc'\nAPI_KEY=shadowed\nPLAIN=x"
+	file, err := ParseDotenv([]byte(src))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	out := string(file.Set("API_KEY", "new $value"))
+	want := "# api\nexport API_KEY=\"old\" # rotate monthly\nNAME='svc'\nAPI_KEY=\"new \\$value\"\nPLAIN=x"
+	if out != want {
+		t.Fatalf("expected %q, got %q", want, out)
+	}
+
+	file, _ = ParseDotenv([]byte(out))
+	out = string(file.Set("NAME", "api"))
+	file, _ = ParseDotenv([]byte(out))
+	out = string(file.Set("NEW_KEY", "two words"))
+	if !strings.Contains(out, "NAME='api'\n") || !strings.HasSuffix(out, "PLAIN=x\nNEW_KEY=\"two words\"\n") {
+		t.Fatalf("unexpected result: %q", out)
+	}
+	if got, _ := ParseDotenv([]byte(out)); got.Values()["API_KEY"] != "new $value" {
+		t.Fatalf("expected escaped value to round-trip, got %q", got.Values()["API_KEY"])
+	}
+	if ValidDotenvKey("1BAD") || ValidDotenvKey("BAD KEY") || !ValidDotenvKey("GOOD_KEY") {
+		t.Fatal("unexpected key validation result")
+	}
+}
@@ -0,0 +1,81 @@
+package domain
+
+import (
+	"math"
+	"regexp"
+	"strings"
+)
+
+const (
+	DefaultEntropyThreshold = 4.0
+	DefaultEntropyMinLength = 20
+)
+
+var (
+	quotedValuePattern   = regexp.MustCompile(`"([^"]*)"|'([^']*)'`)
+	assignedValuePattern = regexp.MustCompile(`[:=]\s*([^\s"',;]+)`)
+	secretValueCharset   = regexp.MustCompile(`^[A-Za-z0-9+/=_\-.~]+$`)
+	integrityHashPrefix  = regexp.MustCompile(`^(sha(1|256|384|512)|md5)-`)
+	quotedKeySuffix      = regexp.MustCompile(`^\s*(:|=[^=])`)
+	dottedNamePattern    = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_\-]*(\.[A-Za-z_][A-Za-z0-9_\-]*)+$`)
+)
+
+func ShannonEntropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+	counts := make(map[rune]int)
+	total := 0
+	for _, r := range s {
+		counts[r]++
+		total++
+	}
+	entropy := 0.0
+	for _, n := range counts {
+		p := float64(n) / float64(total)
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+func FindHighEntropyValue(line string, threshold float64, minLength int) (string, float64, bool) {
+	if threshold <= 0 {
+		return "", 0, false
+	}
+
+	candidates := make([]string, 0, 2)
+	for _, loc := range quotedValuePattern.FindAllStringSubmatchIndex(line, -1) {
+		if quotedKeySuffix.MatchString(line[loc[1]:]) {
+			continue
+		}
+		candidates = append(candidates, line[loc[0]+1:loc[1]-1])
+	}
+	for _, m := range assignedValuePattern.FindAllStringSubmatch(line, -1) {
+		candidates = append(candidates, m[1])
+	}
+
+	for _, candidate := range candidates {
+		candidate = strings.TrimSpace(candidate)
+		if len(candidate) < minLength || !secretValueCharset.MatchString(candidate) || integrityHashPrefix.MatchString(candidate) || looksLikePath(candidate) {
+			continue
+		}
+		if entropy := ShannonEntropy(candidate); entropy >= threshold {
+			return candidate, entropy, true
+		}
+	}
+	return "", 0, false
+}
+
+// looksLikePath reports file paths, URL remainders and dotted names such as
+// hosts or modules. Base64 uses "/" but never alongside ".", "-", "_" or "~".
+func looksLikePath(value string) bool {
+	for _, prefix := range []string{"//", "./", "../", "~/"} {
+		if strings.HasPrefix(value, prefix) {
+			return true
+		}
+	}
+	if strings.Contains(value, "/") && strings.ContainsAny(value, ".-_~") {
+		return true
+	}
+	return dottedNamePattern.MatchString(value)
+}
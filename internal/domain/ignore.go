@@ -0,0 +1,262 @@
+package domain
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	GitIgnoreFile         = ".gitignore"
+	SecretvaultIgnoreFile = ".secretvaultignore"
+)
+
+type ignorePattern struct {
+	segments []string
+	negate   bool
+	dirOnly  bool
+	anchored bool
+}
+
+type ignoreRules struct {
+	base      string
+	patterns  []ignorePattern
+	gitignore bool
+}
+
+type IgnoreMatcher struct {
+	rules []ignoreRules
+}
+
+func NewIgnoreMatcher(root string) (*IgnoreMatcher, error) {
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &IgnoreMatcher{}
+	dirs := []string{abs}
+	if gitRoot, ok := findGitRoot(abs); ok {
+		rules, err := loadIgnoreRules(gitRoot, filepath.Join(gitRoot, ".git", "info", "exclude"), true)
+		if err != nil {
+			return nil, err
+		}
+		m = m.with(rules)
+
+		dirs = dirs[:0]
+		for dir := abs; ; dir = filepath.Dir(dir) {
+			dirs = append([]string{dir}, dirs...)
+			if dir == gitRoot {
+				break
+			}
+		}
+	}
+
+	for _, dir := range dirs {
+		m, err = m.WithDir(dir)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+func (m *IgnoreMatcher) WithDir(dir string) (*IgnoreMatcher, error) {
+	out := m
+	for _, name := range []string{GitIgnoreFile, SecretvaultIgnoreFile} {
+		rules, err := loadIgnoreRules(dir, filepath.Join(dir, name), name == GitIgnoreFile)
+		if err != nil {
+			return nil, err
+		}
+		out = out.with(rules)
+	}
+	return out, nil
+}
+
+// Match reports whether .secretvaultignore excludes the path, and separately
+// whether .gitignore does. Gitignored paths still go through the name rules,
+// because secrets are exactly what tends to be gitignored.
+func (m *IgnoreMatcher) Match(absPath string, isDir bool) (ignored, gitignored bool) {
+	if m == nil {
+		return false, false
+	}
+	for _, rules := range m.rules {
+		rel, ok := ProjectRelativePath(rules.base, absPath)
+		if !ok || rel == "." {
+			continue
+		}
+		rel = filepath.ToSlash(rel)
+		for _, p := range rules.patterns {
+			if p.dirOnly && !isDir {
+				continue
+			}
+			if !p.matches(rel) {
+				continue
+			}
+			if rules.gitignore {
+				gitignored = !p.negate
+			} else {
+				ignored = !p.negate
+			}
+		}
+	}
+	return ignored, gitignored
+}
+
+type PathIgnorer struct {
+	root     string
+	matchers map[string]*IgnoreMatcher
+}
+
+func NewPathIgnorer(root string) (*PathIgnorer, error) {
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	m, err := NewIgnoreMatcher(abs)
+	if err != nil {
+		return nil, err
+	}
+	return &PathIgnorer{root: abs, matchers: map[string]*IgnoreMatcher{abs: m}}, nil
+}
+
+// Ignored reports whether a slash-separated path relative to the root, or one
+// of its parent directories, is excluded by .secretvaultignore. It serves git
+// blobs, which .gitignore never hides: they were committed or staged anyway.
+func (p *PathIgnorer) Ignored(rel string) (bool, error) {
+	dir := p.root
+	matcher := p.matchers[dir]
+	parts := strings.Split(rel, "/")
+	for _, part := range parts[:len(parts)-1] {
+		dir = filepath.Join(dir, part)
+		if ignored, _ := matcher.Match(dir, true); ignored {
+			return true, nil
+		}
+		next, ok := p.matchers[dir]
+		if !ok {
+			var err error
+			if next, err = matcher.WithDir(dir); err != nil {
+				return false, err
+			}
+			p.matchers[dir] = next
+		}
+		matcher = next
+	}
+	ignored, _ := matcher.Match(filepath.Join(p.root, filepath.FromSlash(rel)), false)
+	return ignored, nil
+}
+
+func (m *IgnoreMatcher) with(rules ignoreRules) *IgnoreMatcher {
+	if len(rules.patterns) == 0 {
+		return m
+	}
+	next := make([]ignoreRules, 0, len(m.rules)+1)
+	next = append(next, m.rules...)
+	next = append(next, rules)
+	return &IgnoreMatcher{rules: next}
+}
+
+func (p ignorePattern) matches(rel string) bool {
+	parts := strings.Split(rel, "/")
+	if !p.anchored {
+		return matchSegment(p.segments[0], parts[len(parts)-1])
+	}
+	return matchSegments(p.segments, parts)
+}
+
+func matchSegments(pattern, parts []string) bool {
+	if len(pattern) == 0 {
+		return len(parts) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(parts); i++ {
+			if matchSegments(pattern[1:], parts[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(parts) == 0 || !matchSegment(pattern[0], parts[0]) {
+		return false
+	}
+	return matchSegments(pattern[1:], parts[1:])
+}
+
+func matchSegment(pattern, name string) bool {
+	ok, err := path.Match(pattern, name)
+	return err == nil && ok
+}
+
+func loadIgnoreRules(base, file string, gitignore bool) (ignoreRules, error) {
+	rules := ignoreRules{base: base, gitignore: gitignore}
+	f, err := os.Open(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return rules, nil
+		}
+		return rules, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if p, ok := parseIgnorePattern(scanner.Text()); ok {
+			rules.patterns = append(rules.patterns, p)
+		}
+	}
+	return rules, scanner.Err()
+}
+
+func parseIgnorePattern(line string) (ignorePattern, bool) {
+	line = strings.TrimSuffix(line, "\r")
+	if !strings.HasSuffix(line, `\ `) {
+		line = strings.TrimRight(line, " \t")
+	}
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignorePattern{}, false
+	}
+
+	var p ignorePattern
+	if strings.HasPrefix(line, "!") {
+		p.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		p.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if strings.HasPrefix(line, "**/") {
+		line = strings.TrimPrefix(line, "**/")
+		if !strings.Contains(line, "/") {
+			p.segments = []string{line}
+			return p, line != ""
+		}
+		line = "**/" + line
+	}
+	if strings.Contains(line, "/") {
+		p.anchored = true
+		line = strings.TrimPrefix(line, "/")
+	}
+	if line == "" {
+		return ignorePattern{}, false
+	}
+	p.segments = strings.Split(line, "/")
+	return p, true
+}
+
+func findGitRoot(dir string) (string, bool) {
+	for {
+		if FileExists(filepath.Join(dir, ".git")) {
+			return dir, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
@@ -0,0 +1,98 @@
+package domain
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseIgnorePattern(t *testing.T) {
+	tests := []struct {
+		line     string
+		ok       bool
+		negate   bool
+		dirOnly  bool
+		anchored bool
+	}{
+		{line: "", ok: false},
+		{line: "# comment", ok: false},
+		{line: "*.pem", ok: true},
+		{line: "!keep.pem", ok: true, negate: true},
+		{line: "fixtures/", ok: true, dirOnly: true},
+		{line: "/build", ok: true, anchored: true},
+		{line: "testdata/*.key", ok: true, anchored: true},
+		{line: "**/certs", ok: true},
+		{line: `\#literal`, ok: true},
+	}
+
+	for _, tc := range tests {
+		p, ok := parseIgnorePattern(tc.line)
+		if ok != tc.ok {
+			t.Fatalf("%q: ok mismatch: got %v want %v", tc.line, ok, tc.ok)
+		}
+		if !ok {
+			continue
+		}
+		if p.negate != tc.negate || p.dirOnly != tc.dirOnly || p.anchored != tc.anchored {
+			t.Fatalf("%q: unexpected pattern %+v", tc.line, p)
+		}
+	}
+}
+
+func TestFindSensitiveFilesHonoursIgnoreFiles(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		".gitignore":               "fixtures/\n/generated\n*.pem\n.env\nsecrets/\nlocal.ini\n",
+		".secretvaultignore":       "sub/.env.test\nvendor-keys/\n",
+		".env":                     "A=1\n",
+		"server.pem":               "x\n",
+		"local.ini":                "password = hunter2\n",
+		"fixtures/backend.tfvars":  "db=1\n",
+		"fixtures/settings.ini":    "password = hunter2\n",
+		"generated/app.key":        "x\n",
+		"nested/.gitignore":        "*.tfvars\n",
+		"nested/prod.tfvars":       "db=1\n",
+		"secrets/token.txt":        "x\n",
+		"sub/.env.test":            "A=1\n",
+		"sub/terraform.tfvars":     "db=1\n",
+		"vendor-keys/upstream.pem": "x\n",
+	}
+	for rel, data := range files {
+		abs := filepath.Join(dir, rel)
+		if err := os.MkdirAll(filepath.Dir(abs), 0o755); err != nil {
+			t.Fatalf("mkdir %s: %v", rel, err)
+		}
+		if err := os.WriteFile(abs, []byte(data), 0o600); err != nil {
+			t.Fatalf("write %s: %v", rel, err)
+		}
+	}
+
+	targets, err := FindSensitiveFiles([]string{dir})
+	if err != nil {
+		t.Fatalf("find sensitive files: %v", err)
+	}
+	got := make(map[string]struct{}, len(targets))
+	for _, target := range targets {
+		rel, _ := filepath.Rel(dir, target)
+		got[filepath.ToSlash(rel)] = struct{}{}
+	}
+
+	for _, rel := range []string{".env", "server.pem", "fixtures/backend.tfvars", "generated/app.key", "nested/prod.tfvars", "secrets/token.txt", "sub/terraform.tfvars"} {
+		if _, ok := got[rel]; !ok {
+			t.Fatalf("expected gitignored or plain %s to be detected by name, got %v", rel, targets)
+		}
+	}
+	for _, rel := range []string{"local.ini", "fixtures/settings.ini", "sub/.env.test", "vendor-keys/upstream.pem"} {
+		if _, ok := got[rel]; ok {
+			t.Fatalf("expected %s to be ignored", rel)
+		}
+	}
+
+	all, err := FindSensitiveFilesWithOptions([]string{dir}, ScanOptions{NoIgnore: true})
+	if err != nil {
+		t.Fatalf("find sensitive files without ignores: %v", err)
+	}
+	if len(all) != len(targets)+4 {
+		t.Fatalf("expected --no-ignore to add the 4 ignored files: %d vs %d", len(all), len(targets))
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package domain
+
+import "io/fs"
+
+func fileInode(info fs.FileInfo) uint64 {
+	return 0
+}
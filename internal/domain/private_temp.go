@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func PrivateTempDir(prefix string) (string, error) {
+	base := strings.TrimSpace(os.Getenv("XDG_RUNTIME_DIR"))
+	if info, err := os.Stat(base); base == "" || err != nil || !info.IsDir() {
+		base = os.TempDir()
+	}
+	dir, err := os.MkdirTemp(base, prefix)
+	if err != nil {
+		return "", err
+	}
+	return dir, os.Chmod(dir, 0o700)
+}
+
+func ShredFile(path string) error {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return err
+	}
+	if info.Mode().IsRegular() {
+		if f, err := os.OpenFile(path, os.O_WRONLY, 0); err == nil {
+			zeros := make([]byte, 32*1024)
+			for remaining := info.Size(); remaining > 0; {
+				n := int64(len(zeros))
+				if remaining < n {
+					n = remaining
+				}
+				if _, err := f.Write(zeros[:n]); err != nil {
+					break
+				}
+				remaining -= n
+			}
+			_ = f.Sync()
+			_ = f.Close()
+		}
+	}
+	return os.Remove(path)
+}
+
+func ShredDir(dir string) error {
+	_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			_ = ShredFile(path)
+		}
+		return nil
+	})
+	return os.RemoveAll(dir)
+}
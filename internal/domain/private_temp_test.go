@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPrivateTempDirPrefersRuntimeDirAndShreds(t *testing.T) {
+	runtime := t.TempDir()
+	t.Setenv("XDG_RUNTIME_DIR", runtime)
+
+	dir, err := PrivateTempDir("secretvault-test-")
+	if err != nil {
+		t.Fatalf("temp dir: %v", err)
+	}
+	if filepath.Dir(dir) != runtime {
+		t.Fatalf("expected temp dir under %s, got %s", runtime, dir)
+	}
+	if info, err := os.Stat(dir); err != nil || info.Mode().Perm() != 0o700 {
+		t.Fatalf("expected 0700 temp dir, got %v (%v)", info.Mode().Perm(), err)
+	}
+
+	path := filepath.Join(dir, ".env")
+	if err := os.WriteFile(path, []byte("SECRET=1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".env.swp"), []byte("swap"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ShredDir(dir); err != nil {
+		t.Fatalf("shred: %v", err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Fatalf("expected %s to be removed, got %v", dir, err)
+	}
+}
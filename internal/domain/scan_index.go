@@ -0,0 +1,214 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const scanIndexVersion = 1
+
+// detectionLogicVersion must be bumped whenever a change to the detection code
+// can flip the verdict for a file whose stamp is unchanged. Options and rule
+// tables are fingerprinted on their own; this covers everything else.
+const detectionLogicVersion = 9
+
+type fileStamp struct {
+	Size    int64  `json:"size"`
+	ModTime int64  `json:"mtime_ns"`
+	Inode   uint64 `json:"inode,omitempty"`
+}
+
+type indexedFile struct {
+	Stamp  fileStamp       `json:"stamp"`
+	Reason SensitiveReason `json:"reason"`
+}
+
+type indexedDirEntry struct {
+	Name    string `json:"name"`
+	IsDir   bool   `json:"dir,omitempty"`
+	Regular bool   `json:"regular,omitempty"`
+}
+
+type indexedDir struct {
+	Stamp   fileStamp         `json:"stamp"`
+	Entries []indexedDirEntry `json:"entries"`
+}
+
+type scanIndexFile struct {
+	Version     int                    `json:"version"`
+	Fingerprint string                 `json:"fingerprint"`
+	Files       map[string]indexedFile `json:"files"`
+	Dirs        map[string]indexedDir  `json:"dirs"`
+}
+
+type scanIndex struct {
+	path        string
+	fingerprint string
+	previous    scanIndexFile
+	// writtenAt is the mtime of the stored index. An entry whose mtime is not
+	// older could have changed again within the same timestamp tick after it
+	// was scanned, so it is never trusted.
+	writtenAt int64
+
+	mu    sync.Mutex
+	files map[string]indexedFile
+	dirs  map[string]indexedDir
+}
+
+func loadScanIndex(path string, opts ScanOptions) (*scanIndex, error) {
+	ix := &scanIndex{
+		path:        path,
+		fingerprint: scanRulesFingerprint(opts),
+		files:       map[string]indexedFile{},
+		dirs:        map[string]indexedDir{},
+	}
+	if opts.RebuildIndex {
+		return ix, nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return ix, nil
+		}
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var stored scanIndexFile
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return ix, nil
+	}
+	if stored.Version != scanIndexVersion || stored.Fingerprint != ix.fingerprint {
+		return ix, nil
+	}
+	ix.previous = stored
+	ix.writtenAt = info.ModTime().UnixNano()
+	return ix, nil
+}
+
+func (ix *scanIndex) file(path string, stamp fileStamp) (SensitiveReason, bool) {
+	if ix == nil {
+		return SensitiveReason{}, false
+	}
+	cached, ok := ix.previous.Files[path]
+	if !ok || cached.Stamp != stamp || stamp.ModTime >= ix.writtenAt {
+		return SensitiveReason{}, false
+	}
+	return cached.Reason, true
+}
+
+func (ix *scanIndex) putFile(path string, stamp fileStamp, reason SensitiveReason) {
+	if ix == nil {
+		return
+	}
+	ix.mu.Lock()
+	ix.files[path] = indexedFile{Stamp: stamp, Reason: reason}
+	ix.mu.Unlock()
+}
+
+func (ix *scanIndex) dir(path string, stamp fileStamp) ([]indexedDirEntry, bool) {
+	if ix == nil {
+		return nil, false
+	}
+	cached, ok := ix.previous.Dirs[path]
+	if !ok || cached.Stamp != stamp || stamp.ModTime >= ix.writtenAt {
+		return nil, false
+	}
+	return cached.Entries, true
+}
+
+func (ix *scanIndex) putDir(path string, stamp fileStamp, entries []indexedDirEntry) {
+	if ix == nil {
+		return
+	}
+	ix.mu.Lock()
+	ix.dirs[path] = indexedDir{Stamp: stamp, Entries: entries}
+	ix.mu.Unlock()
+}
+
+func (ix *scanIndex) save(roots []string) error {
+	if ix == nil {
+		return nil
+	}
+
+	out := scanIndexFile{
+		Version:     scanIndexVersion,
+		Fingerprint: ix.fingerprint,
+		Files:       ix.files,
+		Dirs:        ix.dirs,
+	}
+	for path, entry := range ix.previous.Files {
+		if _, ok := out.Files[path]; !ok && !isUnderAnyRoot(path, roots) {
+			out.Files[path] = entry
+		}
+	}
+	for path, entry := range ix.previous.Dirs {
+		if _, ok := out.Dirs[path]; !ok && !isUnderAnyRoot(path, roots) {
+			out.Dirs[path] = entry
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(ix.path), 0o700); err != nil {
+		return err
+	}
+	data, err := json.Marshal(out)
+	if err != nil {
+		return err
+	}
+	return WriteAtomic(ix.path, data, 0o600)
+}
+
+func stampOf(info fs.FileInfo) fileStamp {
+	return fileStamp{
+		Size:    info.Size(),
+		ModTime: info.ModTime().UnixNano(),
+		Inode:   fileInode(info),
+	}
+}
+
+func isUnderAnyRoot(path string, roots []string) bool {
+	for _, root := range roots {
+		if _, ok := ProjectRelativePath(root, path); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func scanRulesFingerprint(opts ScanOptions) string {
+	parts := []string{
+		fmt.Sprintf("logic=%d", detectionLogicVersion),
+		fmt.Sprintf("entropy=%g/%d", opts.EntropyThreshold, opts.EntropyMinLength),
+		fmt.Sprintf("max-bytes=%d", opts.MaxContentBytes),
+		fmt.Sprintf("archives=%d/%d", opts.ArchiveMaxDepth, opts.ArchiveMaxBytes),
+		"names=" + strings.Join(SortedKeys(SensitiveExactNames), ","),
+		"suffixes=" + strings.Join(SensitiveSuffixes, ","),
+		"dirs=" + strings.Join(SortedKeys(SensitiveDirNames), ","),
+		"content-ext=" + strings.Join(SortedKeys(ContentScanExtensions), ","),
+		"content-names=" + strings.Join(SortedKeys(ContentScanNames), ","),
+		"content-skip=" + strings.Join(SortedKeys(ContentScanSkipNames), ","),
+		fmt.Sprintf("source=%t:%s", opts.ScanSource, strings.Join(SortedKeys(SourceScanExtensions), ",")),
+	}
+	detectors := make([]string, 0, len(SecretDetectors))
+	for _, d := range SecretDetectors {
+		detectors = append(detectors, fmt.Sprintf("%s=%s=%t=%s", d.ID, d.Severity, d.ConfigOnly, d.Pattern.String()))
+	}
+	sort.Strings(detectors)
+	parts = append(parts, detectors...)
+	parts = append(parts, "structured="+helmSecretValuesName.String(), "baseline="+opts.Baseline.digest())
+
+	h := sha256.Sum256([]byte(strings.Join(parts, "\n")))
+	return hex.EncodeToString(h[:])
+}
@@ -0,0 +1,135 @@
+package domain
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestScanIndexReusesUnchangedFiles(t *testing.T) {
+	dir := t.TempDir()
+	indexPath := filepath.Join(t.TempDir(), "scan-index.json")
+	path := filepath.Join(dir, "app.yaml")
+	if err := os.WriteFile(path, []byte("password: hunter2\n"), 0o600); err != nil {
+		t.Fatalf("write app.yaml: %v", err)
+	}
+	earlier := time.Now().Add(-time.Minute)
+	if err := os.Chtimes(path, earlier, earlier); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+
+	opts := DefaultScanOptions()
+	opts.IndexPath = indexPath
+	first, err := FindSensitiveFilesWithOptions([]string{dir}, opts)
+	if err != nil {
+		t.Fatalf("first scan: %v", err)
+	}
+	if len(first) != 1 {
+		t.Fatalf("expected 1 detection, got %v", first)
+	}
+	if !FileExists(indexPath) {
+		t.Fatalf("expected scan index to be written")
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if _, err := f.WriteString("greeting: hello!!\n"); err != nil {
+		t.Fatalf("rewrite: %v", err)
+	}
+	f.Close()
+	if err := os.Chtimes(path, info.ModTime(), info.ModTime()); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+
+	cached, err := FindSensitiveFilesWithOptions([]string{dir}, opts)
+	if err != nil {
+		t.Fatalf("cached scan: %v", err)
+	}
+	if len(cached) != 1 {
+		t.Fatalf("expected unchanged stamp to reuse cached result, got %v", cached)
+	}
+
+	opts.RebuildIndex = true
+	rebuilt, err := FindSensitiveFilesWithOptions([]string{dir}, opts)
+	if err != nil {
+		t.Fatalf("rebuild scan: %v", err)
+	}
+	if len(rebuilt) != 0 {
+		t.Fatalf("expected rebuild to re-evaluate content, got %v", rebuilt)
+	}
+
+	opts.RebuildIndex = false
+	if err := os.WriteFile(filepath.Join(dir, "new.pem"), []byte("x\n"), 0o600); err != nil {
+		t.Fatalf("write new.pem: %v", err)
+	}
+	later := time.Now().Add(2 * time.Second)
+	if err := os.Chtimes(dir, later, later); err != nil {
+		t.Fatalf("chtimes dir: %v", err)
+	}
+	updated, err := FindSensitiveFilesWithOptions([]string{dir}, opts)
+	if err != nil {
+		t.Fatalf("updated scan: %v", err)
+	}
+	if len(updated) != 1 || filepath.Base(updated[0]) != "new.pem" {
+		t.Fatalf("expected new file to be picked up, got %v", updated)
+	}
+}
+
+func TestScanIndexInvalidatedByOptions(t *testing.T) {
+	a := DefaultScanOptions()
+	b := DefaultScanOptions()
+	b.EntropyThreshold = 5
+	if scanRulesFingerprint(a) == scanRulesFingerprint(b) {
+		t.Fatalf("expected fingerprint to change with entropy threshold")
+	}
+	b = DefaultScanOptions()
+	b.Workers = a.Workers + 3
+	if scanRulesFingerprint(a) != scanRulesFingerprint(b) {
+		t.Fatalf("worker count should not affect fingerprint")
+	}
+}
+
+func TestScanIndexDistrustsRacyEntries(t *testing.T) {
+	dir := t.TempDir()
+	indexPath := filepath.Join(t.TempDir(), "scan-index.json")
+	path := filepath.Join(dir, "app.yaml")
+	if err := os.WriteFile(path, []byte("greeting: hello!!\n"), 0o600); err != nil {
+		t.Fatalf("write app.yaml: %v", err)
+	}
+	// Same tick as the index write: the file may still change unnoticed.
+	racy := time.Now().Add(time.Hour)
+	if err := os.Chtimes(path, racy, racy); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+
+	opts := DefaultScanOptions()
+	opts.IndexPath = indexPath
+	first, err := FindSensitiveFilesWithOptions([]string{dir}, opts)
+	if err != nil {
+		t.Fatalf("first scan: %v", err)
+	}
+	if len(first) != 0 {
+		t.Fatalf("expected no detection, got %v", first)
+	}
+
+	if err := os.WriteFile(path, []byte("password: hunter2\n"), 0o600); err != nil {
+		t.Fatalf("rewrite: %v", err)
+	}
+	if err := os.Chtimes(path, racy, racy); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+	second, err := FindSensitiveFilesWithOptions([]string{dir}, opts)
+	if err != nil {
+		t.Fatalf("second scan: %v", err)
+	}
+	if len(second) != 1 {
+		t.Fatalf("expected a racy entry to be re-evaluated, got %v", second)
+	}
+}
@@ -0,0 +1,256 @@
+package domain
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+type scanJob struct {
+	path     string
+	isDir    bool
+	isRoot   bool
+	nameOnly bool
+	matcher  *IgnoreMatcher
+}
+
+type scanQueue struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	jobs    []scanJob
+	pending int
+	err     error
+}
+
+func newScanQueue() *scanQueue {
+	q := &scanQueue{}
+	q.cond = sync.NewCond(&q.mu)
+	return q
+}
+
+func (q *scanQueue) push(jobs ...scanJob) {
+	if len(jobs) == 0 {
+		return
+	}
+	q.mu.Lock()
+	q.jobs = append(q.jobs, jobs...)
+	q.pending += len(jobs)
+	q.mu.Unlock()
+	q.cond.Broadcast()
+}
+
+func (q *scanQueue) pop() (scanJob, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for len(q.jobs) == 0 && q.pending > 0 && q.err == nil {
+		q.cond.Wait()
+	}
+	if q.err != nil || len(q.jobs) == 0 {
+		return scanJob{}, false
+	}
+	job := q.jobs[len(q.jobs)-1]
+	q.jobs = q.jobs[:len(q.jobs)-1]
+	return job, true
+}
+
+func (q *scanQueue) done() {
+	q.mu.Lock()
+	q.pending--
+	finished := q.pending == 0
+	q.mu.Unlock()
+	if finished {
+		q.cond.Broadcast()
+	}
+}
+
+func (q *scanQueue) fail(err error) {
+	q.mu.Lock()
+	if q.err == nil {
+		q.err = err
+	}
+	q.mu.Unlock()
+	q.cond.Broadcast()
+}
+
+func (q *scanQueue) cancelled() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.err != nil
+}
+
+func runParallelScan(ctx context.Context, jobs []scanJob, opts ScanOptions, index *scanIndex) (map[string]Detection, error) {
+	workers := opts.Workers
+	if workers < 1 {
+		workers = 1
+	}
+
+	var mu sync.Mutex
+	result := make(map[string]Detection)
+	q := newScanQueue()
+	q.push(jobs...)
+
+	stop := context.AfterFunc(ctx, func() { q.fail(ctx.Err()) })
+	defer stop()
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				job, ok := q.pop()
+				if !ok {
+					return
+				}
+				if !q.cancelled() {
+					if err := processScanJob(q, job, opts, index, &mu, result); err != nil {
+						q.fail(err)
+					}
+				}
+				q.done()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if q.err != nil {
+		return nil, q.err
+	}
+	return result, nil
+}
+
+func processScanJob(q *scanQueue, job scanJob, opts ScanOptions, index *scanIndex, mu *sync.Mutex, result map[string]Detection) error {
+	if !job.isDir {
+		detect := detectSensitiveFileIndexed
+		if job.nameOnly {
+			detect = detectSensitiveFileByName
+		}
+		reason, err := detect(job.path, opts, index)
+		if err != nil {
+			return err
+		}
+		if reason.Matched() || len(reason.Suppressed) > 0 {
+			mu.Lock()
+			result[job.path] = Detection{Path: job.path, Reason: reason}
+			mu.Unlock()
+		}
+		return nil
+	}
+
+	matcher := job.matcher
+	if !opts.NoIgnore && !job.isRoot {
+		var err error
+		matcher, err = matcher.WithDir(job.path)
+		if err != nil {
+			return err
+		}
+	}
+
+	entries, err := readDirIndexed(job.path, index)
+	if err != nil {
+		return err
+	}
+
+	children := make([]scanJob, 0, len(entries))
+	for _, entry := range entries {
+		path := filepath.Join(job.path, entry.Name)
+		name := strings.ToLower(entry.Name)
+		if entry.IsDir {
+			if _, skip := IgnoredDirNames[name]; skip {
+				continue
+			}
+			child := scanJob{path: path, isDir: true, nameOnly: job.nameOnly, matcher: matcher}
+			if !opts.NoIgnore {
+				ignored, gitignored := matcher.Match(path, true)
+				if ignored {
+					continue
+				}
+				child.nameOnly = child.nameOnly || gitignored
+			}
+			children = append(children, child)
+			continue
+		}
+
+		if !entry.Regular {
+			continue
+		}
+		if strings.HasSuffix(name, EncryptedExt) {
+			continue
+		}
+		child := scanJob{path: path, nameOnly: job.nameOnly}
+		if !opts.NoIgnore {
+			ignored, gitignored := matcher.Match(path, false)
+			if ignored {
+				continue
+			}
+			child.nameOnly = child.nameOnly || gitignored
+		}
+		children = append(children, child)
+	}
+	q.push(children...)
+	return nil
+}
+
+func detectSensitiveFileIndexed(path string, opts ScanOptions, index *scanIndex) (SensitiveReason, error) {
+	if index == nil {
+		return detectSensitiveFile(path, opts)
+	}
+
+	info, err := os.Lstat(path)
+	if err != nil {
+		return SensitiveReason{}, err
+	}
+	stamp := stampOf(info)
+	if reason, ok := index.file(path, stamp); ok {
+		index.putFile(path, stamp, reason)
+		return reason, nil
+	}
+
+	reason, err := detectSensitiveFile(path, opts)
+	if err != nil {
+		return SensitiveReason{}, err
+	}
+	index.putFile(path, stamp, reason)
+	return reason, nil
+}
+
+// detectSensitiveFileByName serves gitignored files: only a name, suffix or
+// directory match earns them the full check.
+func detectSensitiveFileByName(path string, opts ScanOptions, _ *scanIndex) (SensitiveReason, error) {
+	if reason, _ := detectBaselinedName(path, opts); !reason.Matched() {
+		return SensitiveReason{}, nil
+	}
+	return detectSensitiveFile(path, opts)
+}
+
+func readDirIndexed(path string, index *scanIndex) ([]indexedDirEntry, error) {
+	var stamp fileStamp
+	if index != nil {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		stamp = stampOf(info)
+		if entries, ok := index.dir(path, stamp); ok {
+			index.putDir(path, stamp, entries)
+			return entries, nil
+		}
+	}
+
+	dirEntries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]indexedDirEntry, 0, len(dirEntries))
+	for _, entry := range dirEntries {
+		entries = append(entries, indexedDirEntry{
+			Name:    entry.Name(),
+			IsDir:   entry.IsDir(),
+			Regular: entry.Type().IsRegular(),
+		})
+	}
+	index.putDir(path, stamp, entries)
+	return entries, nil
+}
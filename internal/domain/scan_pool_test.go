@@ -0,0 +1,97 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestScanSensitiveFilesParallelIsDeterministic(t *testing.T) {
+	dir := t.TempDir()
+	for i := 0; i < 20; i++ {
+		sub := filepath.Join(dir, fmt.Sprintf("pkg%02d", i), "config")
+		if err := os.MkdirAll(sub, 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(sub, "app.yaml"), []byte("password: hunter2\n"), 0o600); err != nil {
+			t.Fatalf("write app.yaml: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(sub, "readme.txt"), []byte("hello\n"), 0o600); err != nil {
+			t.Fatalf("write readme.txt: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(sub, fmt.Sprintf("svc%02d.pem", i)), []byte("x\n"), 0o600); err != nil {
+			t.Fatalf("write pem: %v", err)
+		}
+	}
+
+	serial := DefaultScanOptions()
+	serial.Workers = 1
+	want, err := FindSensitiveFilesWithOptions([]string{dir}, serial)
+	if err != nil {
+		t.Fatalf("serial scan: %v", err)
+	}
+	if len(want) != 40 {
+		t.Fatalf("expected 40 detections, got %d", len(want))
+	}
+
+	parallel := DefaultScanOptions()
+	parallel.Workers = 8
+	for run := 0; run < 5; run++ {
+		got, err := FindSensitiveFilesWithOptions([]string{dir}, parallel)
+		if err != nil {
+			t.Fatalf("parallel scan: %v", err)
+		}
+		if len(got) != len(want) {
+			t.Fatalf("length mismatch: got %d want %d", len(got), len(want))
+		}
+		for i := range got {
+			if got[i] != want[i] {
+				t.Fatalf("order mismatch at %d: got %q want %q", i, got[i], want[i])
+			}
+		}
+	}
+}
+
+func TestScanSensitiveFilesMissingRoot(t *testing.T) {
+	if _, err := ScanSensitiveFiles([]string{filepath.Join(t.TempDir(), "missing")}, DefaultScanOptions()); err == nil {
+		t.Fatalf("expected error for missing root")
+	}
+}
+
+func TestScanSensitiveFilesStopsWhenCancelled(t *testing.T) {
+	dir := t.TempDir()
+	line := strings.Repeat("greeting: hello world and welcome\n", 4096)
+	for i := 0; i < 100; i++ {
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("app%03d.yaml", i)), []byte(line), 0o600); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	opts := DefaultScanOptions()
+	opts.Workers = 2
+	before := runtime.NumGoroutine()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(5*time.Millisecond, cancel)
+	start := time.Now()
+	_, err := ScanSensitiveFilesContext(ctx, []string{dir}, opts)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("scan took %v to stop after cancel", elapsed)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before {
+		if time.Now().After(deadline) {
+			t.Fatalf("leaked goroutines: %d before, %d after", before, runtime.NumGoroutine())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,228 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	DockerConfigAuthDetectorID      = "docker-config-auth"
+	KubernetesSecretDetectorID      = "kubernetes-secret"
+	HelmSecretValuesDetectorID      = "helm-secret-values"
+	KubeconfigCredentialsDetectorID = "kubeconfig-credentials"
+	maxStructuredDocumentBytes      = 10 << 20
+	maxStructuredDocumentsCount     = 100
+)
+
+var (
+	helmSecretValuesName = regexp.MustCompile(`^values[-_.]([a-z0-9_-]+[-_.])?secrets?([-_.][a-z0-9_-]+)?\.ya?ml$|^secrets([-_.][a-z0-9_-]+)?\.ya?ml$`)
+
+	kubeconfigUserSecretKeys   = []string{"token", "client-key-data", "client-key", "password"}
+	kubeconfigAuthProviderKeys = []string{"access-token", "refresh-token", "client-secret", "id-token"}
+)
+
+type structuredMatch struct {
+	reason  SensitiveReason
+	locator string
+}
+
+func isStructuredCandidate(path string) bool {
+	base := strings.ToLower(filepath.Base(path))
+	if _, skip := ContentScanSkipNames[base]; skip {
+		return false
+	}
+	switch strings.ToLower(filepath.Ext(base)) {
+	case ".json", ".yaml", ".yml":
+		return true
+	}
+	if strings.HasPrefix(base, "kubeconfig") {
+		return true
+	}
+	return base == "config" && strings.EqualFold(filepath.Base(filepath.Dir(path)), ".kube")
+}
+
+func detectStructuredSecrets(path string, data []byte) []structuredMatch {
+	docs := decodeStructuredDocuments(path, data)
+	var matches []structuredMatch
+	helm := helmSecretValuesName.MatchString(strings.ToLower(filepath.Base(path)))
+	for _, doc := range docs {
+		root := doc
+		if root.Kind == yaml.DocumentNode && len(root.Content) > 0 {
+			root = root.Content[0]
+		}
+		if root.Kind != yaml.MappingNode || mappingValue(root, "sops") != nil || mappingValue(root, StructuredMetadataKey) != nil {
+			continue
+		}
+		matches = append(matches, dockerConfigMatches(root)...)
+		matches = append(matches, kubernetesSecretMatches(root)...)
+		matches = append(matches, kubeconfigMatches(root)...)
+		if helm && len(root.Content) > 0 {
+			matches = append(matches, structuredMatch{
+				reason:  structuredReason(HelmSecretValuesDetectorID, "Helm secret values file", SeverityHigh, root.Content[0]),
+				locator: "values",
+			})
+			helm = false
+		}
+	}
+	return matches
+}
+
+func decodeStructuredDocuments(path string, data []byte) []*yaml.Node {
+	var docs []*yaml.Node
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	for len(docs) < maxStructuredDocumentsCount {
+		var doc yaml.Node
+		err := dec.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			return docs
+		}
+		if err != nil {
+			break
+		}
+		docs = append(docs, &doc)
+	}
+	if len(docs) > 0 || !strings.EqualFold(filepath.Ext(path), ".json") {
+		return docs
+	}
+
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil
+	}
+	var doc yaml.Node
+	if err := doc.Encode(value); err != nil {
+		return nil
+	}
+	return []*yaml.Node{&doc}
+}
+
+func dockerConfigMatches(root *yaml.Node) []structuredMatch {
+	auths := mappingValue(root, "auths")
+	if auths == nil || auths.Kind != yaml.MappingNode {
+		return nil
+	}
+	var matches []structuredMatch
+	for i := 0; i+1 < len(auths.Content); i += 2 {
+		registry, entry := auths.Content[i], auths.Content[i+1]
+		for _, key := range []string{"auth", "identitytoken", "password"} {
+			if v := mappingValue(entry, key); nonEmptyScalar(v) {
+				matches = append(matches, structuredMatch{
+					reason:  structuredReason(DockerConfigAuthDetectorID, "container registry credentials (auths."+registry.Value+"."+key+")", SeverityCritical, v),
+					locator: "auths." + registry.Value + "." + key,
+				})
+				break
+			}
+		}
+	}
+	return matches
+}
+
+func kubernetesSecretMatches(root *yaml.Node) []structuredMatch {
+	kind := scalarValue(mappingValue(root, "kind"))
+	if kind == "List" || strings.HasSuffix(kind, "List") {
+		var matches []structuredMatch
+		if items := mappingValue(root, "items"); items != nil && items.Kind == yaml.SequenceNode {
+			for _, item := range items.Content {
+				if item.Kind == yaml.MappingNode {
+					matches = append(matches, kubernetesSecretMatches(item)...)
+				}
+			}
+		}
+		return matches
+	}
+	if kind != "Secret" || scalarValue(mappingValue(root, "apiVersion")) == "" {
+		return nil
+	}
+
+	name := scalarValue(mappingValue(mappingValue(root, "metadata"), "name"))
+	for _, key := range []string{"data", "stringData"} {
+		data := mappingValue(root, key)
+		if data == nil || data.Kind != yaml.MappingNode || len(data.Content) == 0 {
+			continue
+		}
+		return []structuredMatch{{
+			reason:  structuredReason(KubernetesSecretDetectorID, "Kubernetes Secret manifest with "+key, SeverityHigh, data.Content[0]),
+			locator: "secret/" + name + "." + key,
+		}}
+	}
+	return nil
+}
+
+func kubeconfigMatches(root *yaml.Node) []structuredMatch {
+	users := mappingValue(root, "users")
+	if users == nil || users.Kind != yaml.SequenceNode {
+		return nil
+	}
+	if scalarValue(mappingValue(root, "kind")) != "Config" && mappingValue(root, "clusters") == nil {
+		return nil
+	}
+
+	var matches []structuredMatch
+	for _, item := range users.Content {
+		name := scalarValue(mappingValue(item, "name"))
+		user := mappingValue(item, "user")
+		if user == nil {
+			continue
+		}
+		key, v := firstNonEmpty(user, kubeconfigUserSecretKeys)
+		if v == nil {
+			key, v = firstNonEmpty(mappingValue(mappingValue(user, "auth-provider"), "config"), kubeconfigAuthProviderKeys)
+		}
+		if v == nil {
+			continue
+		}
+		matches = append(matches, structuredMatch{
+			reason:  structuredReason(KubeconfigCredentialsDetectorID, "kubeconfig user credentials (users["+name+"].user."+key+")", SeverityCritical, v),
+			locator: "users." + name + "." + key,
+		})
+	}
+	return matches
+}
+
+func structuredReason(id, description, severity string, node *yaml.Node) SensitiveReason {
+	return SensitiveReason{
+		Rule:     id,
+		Pattern:  description,
+		Severity: severity,
+		Line:     node.Line,
+	}
+}
+
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func scalarValue(node *yaml.Node) string {
+	if node == nil || node.Kind != yaml.ScalarNode {
+		return ""
+	}
+	return node.Value
+}
+
+func nonEmptyScalar(node *yaml.Node) bool {
+	return strings.TrimSpace(scalarValue(node)) != ""
+}
+
+func firstNonEmpty(node *yaml.Node, keys []string) (string, *yaml.Node) {
+	for _, key := range keys {
+		if v := mappingValue(node, key); nonEmptyScalar(v) {
+			return key, v
+		}
+	}
+	return "", nil
+}
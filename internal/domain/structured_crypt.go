@@ -0,0 +1,371 @@
+package domain
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	StructuredMetadataKey = "svault"
+	structuredMACVersion  = "1"
+)
+
+var ErrStructuredMACMismatch = errors.New("MAC mismatch: document was modified outside secretvault")
+
+func IsStructuredLockFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json", ".yaml", ".yml":
+		return true
+	}
+	return false
+}
+
+func EncryptStructuredValues(path string, data, key []byte, resign bool) ([]byte, int, error) {
+	root, err := parseStructuredLockDocument(path, data)
+	if err != nil {
+		return nil, 0, err
+	}
+	signed := mappingValue(documentRoot(root), StructuredMetadataKey) != nil
+	var macErr error
+	if signed {
+		macErr = verifyStructuredMAC(root, key, true)
+		if macErr != nil && (!resign || !errors.Is(macErr, ErrStructuredMACMismatch)) {
+			return nil, 0, macErr
+		}
+	}
+
+	count := 0
+	err = walkStructuredLeaves(root, func(pointer string, leaf *yaml.Node) error {
+		if leaf.Tag == "!!null" || IsValueToken(leaf.Value) {
+			return nil
+		}
+		token, err := sealValueToken(key, pointer, structuredLeafType(leaf)+":"+leaf.Value)
+		if err != nil {
+			return err
+		}
+		leaf.Value, leaf.Tag, leaf.Style = token, "!!str", 0
+		count++
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	if count == 0 && signed && macErr == nil {
+		return data, 0, nil
+	}
+
+	mac, err := structuredMAC(root, key, false)
+	if err != nil {
+		return nil, 0, err
+	}
+	setStructuredMetadata(root, mac)
+	out, err := encodeStructuredDocument(path, root)
+	if err != nil {
+		return nil, 0, err
+	}
+	return out, count, nil
+}
+
+func DecryptStructuredValues(path string, data, key []byte) ([]byte, int, error) {
+	root, err := parseStructuredLockDocument(path, data)
+	if err != nil {
+		return nil, 0, err
+	}
+	if mappingValue(documentRoot(root), StructuredMetadataKey) == nil {
+		return data, 0, nil
+	}
+	if err := verifyStructuredMAC(root, key, false); err != nil {
+		return nil, 0, err
+	}
+
+	count := 0
+	err = walkStructuredLeaves(root, func(pointer string, leaf *yaml.Node) error {
+		if !IsValueToken(leaf.Value) {
+			return nil
+		}
+		if err := openStructuredLeaf(key, pointer, leaf); err != nil {
+			return err
+		}
+		count++
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	removeStructuredMetadata(root)
+	out, err := encodeStructuredDocument(path, root)
+	if err != nil {
+		return nil, 0, err
+	}
+	return out, count, nil
+}
+
+func HasStructuredTokens(path string, data []byte) bool {
+	root, err := parseStructuredLockDocument(path, data)
+	if err != nil {
+		return false
+	}
+	return mappingValue(documentRoot(root), StructuredMetadataKey) != nil
+}
+
+func IsStructuredFullyEncrypted(path string, data []byte) bool {
+	root, err := parseStructuredLockDocument(path, data)
+	if err != nil || mappingValue(documentRoot(root), StructuredMetadataKey) == nil {
+		return false
+	}
+	plain := false
+	_ = walkStructuredLeaves(root, func(_ string, leaf *yaml.Node) error {
+		if leaf.Tag != "!!null" && !IsValueToken(leaf.Value) {
+			plain = true
+		}
+		return nil
+	})
+	return !plain
+}
+
+func parseStructuredLockDocument(path string, data []byte) (*yaml.Node, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	var doc yaml.Node
+	if err := dec.Decode(&doc); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%s is empty", filepath.Base(path))
+		}
+		return nil, fmt.Errorf("parse %s: %w", filepath.Base(path), err)
+	}
+	var extra yaml.Node
+	if err := dec.Decode(&extra); !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("parse %s: multi-document files are not supported", filepath.Base(path))
+	}
+	if documentRoot(&doc).Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("parse %s: top level must be a mapping", filepath.Base(path))
+	}
+	return &doc, nil
+}
+
+func documentRoot(node *yaml.Node) *yaml.Node {
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		return node.Content[0]
+	}
+	return node
+}
+
+func walkStructuredLeaves(doc *yaml.Node, fn func(pointer string, leaf *yaml.Node) error) error {
+	var walk func(node *yaml.Node, pointer string) error
+	walk = func(node *yaml.Node, pointer string) error {
+		switch node.Kind {
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				name := node.Content[i].Value
+				if pointer == "" && name == StructuredMetadataKey {
+					continue
+				}
+				if err := walk(node.Content[i+1], pointer+"/"+escapePointerToken(name)); err != nil {
+					return err
+				}
+			}
+		case yaml.SequenceNode:
+			for i, child := range node.Content {
+				if err := walk(child, pointer+"/"+strconv.Itoa(i)); err != nil {
+					return err
+				}
+			}
+		case yaml.ScalarNode:
+			return fn(pointer, node)
+		}
+		return nil
+	}
+	return walk(documentRoot(doc), "")
+}
+
+func escapePointerToken(name string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(name, "~", "~0"), "/", "~1")
+}
+
+func structuredLeafType(leaf *yaml.Node) string {
+	switch leaf.ShortTag() {
+	case "!!int":
+		return "int"
+	case "!!float":
+		return "float"
+	case "!!bool":
+		return "bool"
+	default:
+		return "str"
+	}
+}
+
+func openStructuredLeaf(key []byte, pointer string, leaf *yaml.Node) error {
+	payload, err := openValueToken(key, pointer, leaf.Value)
+	if err != nil {
+		return err
+	}
+	kind, value, ok := strings.Cut(payload, ":")
+	if !ok {
+		return fmt.Errorf("decrypt %s: malformed payload", pointer)
+	}
+	leaf.Value, leaf.Tag, leaf.Style = value, "!!"+kind, 0
+	if kind == "str" && strings.Contains(value, "\n") {
+		leaf.Style = yaml.LiteralStyle
+	}
+	return nil
+}
+
+// structuredMAC covers every key path and plaintext value. With sealedOnly,
+// plaintext leaves are left out, which gives the MAC the document had before
+// new values were added to it.
+func structuredMAC(doc *yaml.Node, key []byte, sealedOnly bool) (string, error) {
+	macKey := sha256.Sum256(append([]byte(ValueTokenPrefix+"mac\x00"), key...))
+	mac := hmac.New(sha256.New, macKey[:])
+	err := walkStructuredLeaves(doc, func(pointer string, leaf *yaml.Node) error {
+		if sealedOnly && leaf.Tag != "!!null" && !IsValueToken(leaf.Value) {
+			return nil
+		}
+		kind, value := "null", ""
+		if leaf.Tag != "!!null" {
+			kind, value = structuredLeafType(leaf), leaf.Value
+		}
+		if IsValueToken(leaf.Value) {
+			payload, err := openValueToken(key, pointer, leaf.Value)
+			if err != nil {
+				return err
+			}
+			kind, value, _ = strings.Cut(payload, ":")
+		}
+		fmt.Fprintf(mac, "%s\x00%s\x00%d:%s\x00", pointer, kind, len(value), value)
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+func verifyStructuredMAC(doc *yaml.Node, key []byte, sealedOnly bool) error {
+	meta := mappingValue(documentRoot(doc), StructuredMetadataKey)
+	stored := scalarValue(mappingValue(meta, "mac"))
+	if stored == "" {
+		return ErrStructuredMACMismatch
+	}
+	want, err := structuredMAC(doc, key, sealedOnly)
+	if err != nil {
+		return err
+	}
+	if !hmac.Equal([]byte(stored), []byte(want)) {
+		return ErrStructuredMACMismatch
+	}
+	return nil
+}
+
+func setStructuredMetadata(doc *yaml.Node, mac string) {
+	removeStructuredMetadata(doc)
+	root := documentRoot(doc)
+	meta := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Content: []*yaml.Node{
+		{Kind: yaml.ScalarNode, Tag: "!!str", Value: "version"},
+		{Kind: yaml.ScalarNode, Tag: "!!str", Value: structuredMACVersion, Style: yaml.DoubleQuotedStyle},
+		{Kind: yaml.ScalarNode, Tag: "!!str", Value: "mac"},
+		{Kind: yaml.ScalarNode, Tag: "!!str", Value: mac},
+	}}
+	root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: StructuredMetadataKey}, meta)
+}
+
+func removeStructuredMetadata(doc *yaml.Node) {
+	root := documentRoot(doc)
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == StructuredMetadataKey {
+			root.Content = append(root.Content[:i], root.Content[i+2:]...)
+			return
+		}
+	}
+}
+
+func encodeStructuredDocument(path string, doc *yaml.Node) ([]byte, error) {
+	var buf bytes.Buffer
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		if err := writeJSONNode(&buf, documentRoot(doc), ""); err != nil {
+			return nil, err
+		}
+		buf.WriteByte('\n')
+		return buf.Bytes(), nil
+	}
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeJSONNode(buf *bytes.Buffer, node *yaml.Node, indent string) error {
+	inner := indent + "  "
+	switch node.Kind {
+	case yaml.MappingNode:
+		if len(node.Content) == 0 {
+			buf.WriteString("{}")
+			return nil
+		}
+		buf.WriteString("{\n")
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			buf.WriteString(inner)
+			writeJSONString(buf, node.Content[i].Value)
+			buf.WriteString(": ")
+			if err := writeJSONNode(buf, node.Content[i+1], inner); err != nil {
+				return err
+			}
+			if i+2 < len(node.Content) {
+				buf.WriteByte(',')
+			}
+			buf.WriteByte('\n')
+		}
+		buf.WriteString(indent + "}")
+	case yaml.SequenceNode:
+		if len(node.Content) == 0 {
+			buf.WriteString("[]")
+			return nil
+		}
+		buf.WriteString("[\n")
+		for i, child := range node.Content {
+			buf.WriteString(inner)
+			if err := writeJSONNode(buf, child, inner); err != nil {
+				return err
+			}
+			if i+1 < len(node.Content) {
+				buf.WriteByte(',')
+			}
+			buf.WriteByte('\n')
+		}
+		buf.WriteString(indent + "]")
+	case yaml.ScalarNode:
+		switch node.ShortTag() {
+		case "!!null":
+			buf.WriteString("null")
+		case "!!bool", "!!int", "!!float":
+			buf.WriteString(node.Value)
+		default:
+			writeJSONString(buf, node.Value)
+		}
+	default:
+		return fmt.Errorf("unsupported JSON node at line %d", node.Line)
+	}
+	return nil
+}
+
+func writeJSONString(buf *bytes.Buffer, value string) {
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(value)
+	buf.Truncate(buf.Len() - 1)
+}
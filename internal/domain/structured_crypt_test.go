@@ -0,0 +1,109 @@
+package domain
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestStructuredValueEncryptionRoundTrip(t *testing.T) {
+	key := bytes.Repeat([]byte{3}, 32)
+	cases := map[string]string{
+		"secrets.yaml":     "# db\ndb:\n  user: admin\n  password: hunter2 # prod\n  port: 5432\n  tls: true\n  empty: null\nkeys:\n  - sk_live_abc\n  - \"1234\"\n",
+		"credentials.json": "{\n  \"client_id\": \"abc\",\n  \"nested\": {\n    \"n\": 1.5,\n    \"ok\": false,\n    \"list\": []\n  }\n}\n",
+	}
+	for path, src := range cases {
+		locked, n, err := EncryptStructuredValues(path, []byte(src), key, false)
+		if err != nil {
+			t.Fatalf("%s: encrypt: %v", path, err)
+		}
+		if n == 0 || strings.Contains(string(locked), "hunter2") || strings.Contains(string(locked), `"abc"`) {
+			t.Fatalf("%s: expected values to be encrypted, got %s", path, locked)
+		}
+		if !IsStructuredFullyEncrypted(path, locked) {
+			t.Fatalf("%s: expected document to be fully encrypted", path)
+		}
+
+		again, n, err := EncryptStructuredValues(path, locked, key, false)
+		if err != nil || n != 0 || !bytes.Equal(again, locked) {
+			t.Fatalf("%s: expected re-lock to be a no-op, got n=%d err=%v", path, n, err)
+		}
+
+		plain, _, err := DecryptStructuredValues(path, locked, key)
+		if err != nil {
+			t.Fatalf("%s: decrypt: %v", path, err)
+		}
+		if string(plain) != src {
+			t.Fatalf("%s: expected %q, got %q", path, src, plain)
+		}
+	}
+}
+
+func TestStructuredValueMACDetectsRemovedKeys(t *testing.T) {
+	key := bytes.Repeat([]byte{3}, 32)
+	locked, _, err := EncryptStructuredValues("secrets.yaml", []byte("a: one\nb: two\n"), key, false)
+	if err != nil {
+		t.Fatalf("encrypt: %v", err)
+	}
+	lines := strings.Split(string(locked), "\n")
+	tampered := []byte(strings.Join(lines[1:], "\n"))
+	if _, _, err := DecryptStructuredValues("secrets.yaml", tampered, key); !errors.Is(err, ErrStructuredMACMismatch) {
+		t.Fatalf("expected MAC mismatch, got %v", err)
+	}
+	if _, _, err := EncryptStructuredValues("secrets.yaml", tampered, key, false); !errors.Is(err, ErrStructuredMACMismatch) {
+		t.Fatalf("expected lock to refuse a tampered document, got %v", err)
+	}
+	tamperedAndEdited := append(append([]byte(nil), tampered...), []byte("\nc: three\n")...)
+	if _, _, err := EncryptStructuredValues("secrets.yaml", tamperedAndEdited, key, false); !errors.Is(err, ErrStructuredMACMismatch) {
+		t.Fatalf("expected lock to refuse a tampered document with a new value, got %v", err)
+	}
+
+	edited := append(append([]byte(nil), locked...), []byte("c: three\n")...)
+	if _, n, err := EncryptStructuredValues("secrets.yaml", edited, key, false); err != nil || n != 1 {
+		t.Fatalf("expected new plaintext value to be locked, got n=%d err=%v", n, err)
+	}
+
+	resigned, n, err := EncryptStructuredValues("secrets.yaml", tamperedAndEdited, key, true)
+	if err != nil || n != 1 {
+		t.Fatalf("expected resign to accept the document, got n=%d err=%v", n, err)
+	}
+	plain, _, err := DecryptStructuredValues("secrets.yaml", resigned, key)
+	if err != nil || strings.Contains(string(plain), "a:") || !strings.Contains(string(plain), "c: three") {
+		t.Fatalf("unexpected resigned document %q (err %v)", plain, err)
+	}
+}
+
+func TestMatchesLockPattern(t *testing.T) {
+	patterns := []string{"secrets.*.yaml", "config/credentials.json"}
+	for path, want := range map[string]bool{
+		"/repo/deploy/secrets.prod.yaml": true,
+		"/repo/config/credentials.json":  true,
+		"/repo/credentials.json":         false,
+		"/repo/secrets.prod.txt":         false,
+	} {
+		if got := MatchesLockPattern("/repo", path, patterns); got != want {
+			t.Fatalf("%s: expected %v, got %v", path, want, got)
+		}
+	}
+}
+
+func TestValueLockedPrecheckIsBounded(t *testing.T) {
+	key := bytes.Repeat([]byte{4}, 32)
+	locked, _, err := EncryptStructuredValues("secrets.yaml", []byte("a: one\nb: two\n"), key, false)
+	if err != nil {
+		t.Fatalf("encrypt: %v", err)
+	}
+
+	opts := DefaultScanOptions()
+	if data, ok, err := readValueLocked("secrets.yaml", bytes.NewReader(locked), opts); err != nil || !ok || len(data) != len(locked) {
+		t.Fatalf("expected locked document to be recognised, got ok=%v err=%v", ok, err)
+	}
+	opts.MaxContentBytes = 16
+	if data, ok, err := readValueLocked("secrets.yaml", bytes.NewReader(locked), opts); err != nil || ok || len(data) != 16 {
+		t.Fatalf("expected read to stop at the content cap, got %d bytes ok=%v err=%v", len(data), ok, err)
+	}
+	if valueLockCandidate("package-lock.json") || !valueLockCandidate("config/app.json") {
+		t.Fatal("expected lockfiles to be skipped before any read")
+	}
+}
@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStructuredDetectors(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name string
+		data string
+		rule string
+		line int
+	}{
+		{
+			name: "docker/config.json",
+			data: "{\n\t\"auths\": {\n\t\t\"registry.example.com\": {\n\t\t\t\"auth\": \"dXNlcjpwYXNz\"\n\t\t}\n\t}\n}\n",
+			rule: DockerConfigAuthDetectorID,
+		},
+		{
+			name: "docker/helpers.json",
+			data: "{\"auths\": {\"ghcr.io\": {}}, \"credsStore\": \"desktop\"}\n",
+		},
+		{
+			name: "k8s/db.yaml",
+			data: "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: cfg\n---\napiVersion: v1\nkind: Secret\nmetadata:\n  name: db\ndata:\n  url: cG9zdGdyZXM=\n",
+			rule: KubernetesSecretDetectorID,
+			line: 11,
+		},
+		{
+			name: "k8s/empty-secret.yaml",
+			data: "apiVersion: v1\nkind: Secret\nmetadata:\n  name: placeholder\ntype: Opaque\n",
+		},
+		{
+			name: "chart/values-secret.yaml",
+			data: "postgresql:\n  auth:\n    enablePostgresUser: true\n",
+			rule: HelmSecretValuesDetectorID,
+			line: 1,
+		},
+		{
+			name: "chart/values.secrets.sops.yaml",
+			data: "db: ENC[AES256_GCM,data:abc]\nsops:\n  version: 3.8.1\n",
+		},
+		{
+			name: "clusters/kubeconfig-prod",
+			data: "apiVersion: v1\nkind: Config\nclusters:\n- name: prod\n  cluster:\n    server: https://k8s.example.com\nusers:\n- name: admin\n  user:\n    token: abc123\n",
+			rule: KubeconfigCredentialsDetectorID,
+			line: 10,
+		},
+		{
+			name: "clusters/kubeconfig-exec.yaml",
+			data: "apiVersion: v1\nkind: Config\nclusters: []\nusers:\n- name: sso\n  user:\n    exec:\n      command: aws\n",
+		},
+	}
+
+	opts := DefaultScanOptions()
+	opts.EntropyThreshold = 0
+	for _, tc := range tests {
+		path := filepath.Join(dir, filepath.FromSlash(tc.name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(tc.data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		reason, err := detectSensitiveFile(path, opts)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if reason.Rule != tc.rule {
+			t.Fatalf("%s: expected rule %q, got %+v", tc.name, tc.rule, reason)
+		}
+		if tc.line > 0 && reason.Line != tc.line {
+			t.Fatalf("%s: expected line %d, got %d", tc.name, tc.line, reason.Line)
+		}
+	}
+}
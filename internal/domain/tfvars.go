@@ -0,0 +1,345 @@
+package domain
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type TfvarsAttribute struct {
+	Name  string
+	Raw   string
+	Plain bool
+	Line  int
+	start int
+	end   int
+}
+
+type TfvarsFile struct {
+	data       string
+	Attributes []TfvarsAttribute
+}
+
+func IsTfvarsFile(path string) bool {
+	return strings.HasSuffix(strings.ToLower(filepath.Base(path)), ".tfvars")
+}
+
+func TfvarsVariables() []string {
+	return SplitLockPatterns(os.Getenv("SECRETVAULT_TFVARS_VARIABLES"))
+}
+
+func ParseTfvars(data []byte) (*TfvarsFile, error) {
+	p := tfvarsParser{src: string(data), line: 1}
+	file := &TfvarsFile{data: p.src}
+	for {
+		p.skipSpaceAndComments(true)
+		if p.eof() {
+			return file, nil
+		}
+		attr, err := p.attribute()
+		if err != nil {
+			return nil, err
+		}
+		file.Attributes = append(file.Attributes, attr)
+	}
+}
+
+func (f *TfvarsFile) ReplaceRaw(replace func(TfvarsAttribute) (string, bool)) []byte {
+	var b strings.Builder
+	last := 0
+	for _, attr := range f.Attributes {
+		if !attr.Plain {
+			continue
+		}
+		raw, ok := replace(attr)
+		if !ok {
+			continue
+		}
+		b.WriteString(f.data[last:attr.start])
+		b.WriteString(raw)
+		last = attr.end
+	}
+	b.WriteString(f.data[last:])
+	return []byte(b.String())
+}
+
+func MatchesTfvarsVariable(name string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func EncryptTfvarsValues(data, key []byte, variables []string) ([]byte, int, error) {
+	file, err := ParseTfvars(data)
+	if err != nil {
+		return nil, 0, err
+	}
+	count := 0
+	var encErr error
+	out := file.ReplaceRaw(func(attr TfvarsAttribute) (string, bool) {
+		if encErr != nil || attr.Raw == "" || IsValueToken(attr.Raw) || !MatchesTfvarsVariable(attr.Name, variables) {
+			return "", false
+		}
+		token, err := sealValueToken(key, attr.Name, attr.Raw)
+		if err != nil {
+			encErr = err
+			return "", false
+		}
+		count++
+		return token, true
+	})
+	if encErr != nil {
+		return nil, 0, encErr
+	}
+	return out, count, nil
+}
+
+func DecryptTfvarsValues(data, key []byte) ([]byte, int, error) {
+	file, err := ParseTfvars(data)
+	if err != nil {
+		return nil, 0, err
+	}
+	count := 0
+	var decErr error
+	out := file.ReplaceRaw(func(attr TfvarsAttribute) (string, bool) {
+		if decErr != nil || !IsValueToken(attr.Raw) {
+			return "", false
+		}
+		raw, err := openValueToken(key, attr.Name, attr.Raw)
+		if err != nil {
+			decErr = err
+			return "", false
+		}
+		count++
+		return raw, true
+	})
+	if decErr != nil {
+		return nil, 0, decErr
+	}
+	return out, count, nil
+}
+
+func HasTfvarsTokens(data []byte) bool {
+	file, err := ParseTfvars(data)
+	if err != nil {
+		return false
+	}
+	for _, attr := range file.Attributes {
+		if attr.Plain && IsValueToken(attr.Raw) {
+			return true
+		}
+	}
+	return false
+}
+
+func HasTfvarsVariables(data []byte, variables []string) bool {
+	file, err := ParseTfvars(data)
+	if err != nil {
+		return false
+	}
+	for _, attr := range file.Attributes {
+		if attr.Plain && MatchesTfvarsVariable(attr.Name, variables) {
+			return true
+		}
+	}
+	return false
+}
+
+type tfvarsParser struct {
+	src  string
+	pos  int
+	line int
+}
+
+func (p *tfvarsParser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *tfvarsParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("line %d: %s", p.line, fmt.Sprintf(format, args...))
+}
+
+func (p *tfvarsParser) skipSpaceAndComments(newlines bool) {
+	for !p.eof() {
+		c := p.src[p.pos]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r':
+			p.pos++
+		case c == '\n' && newlines:
+			p.pos++
+			p.line++
+		case c == '#' || strings.HasPrefix(p.src[p.pos:], "//"):
+			for !p.eof() && p.src[p.pos] != '\n' {
+				p.pos++
+			}
+		case strings.HasPrefix(p.src[p.pos:], "/*"):
+			end := strings.Index(p.src[p.pos+2:], "*/")
+			if end < 0 {
+				end = len(p.src) - p.pos - 2
+			}
+			p.line += strings.Count(p.src[p.pos:p.pos+2+end], "\n")
+			p.pos = min(len(p.src), p.pos+end+4)
+		default:
+			return
+		}
+	}
+}
+
+func (p *tfvarsParser) attribute() (TfvarsAttribute, error) {
+	attr := TfvarsAttribute{Line: p.line}
+	start := p.pos
+	for !p.eof() && isTfvarsNameChar(p.src[p.pos], p.pos == start) {
+		p.pos++
+	}
+	if p.pos == start {
+		return attr, p.errorf("expected variable name")
+	}
+	attr.Name = p.src[start:p.pos]
+	p.skipSpaceAndComments(false)
+	if p.eof() || p.src[p.pos] != '=' {
+		return attr, p.errorf("expected '=' after %s", attr.Name)
+	}
+	p.pos++
+	p.skipSpaceAndComments(false)
+
+	if !p.eof() && p.src[p.pos] == '"' {
+		attr.start = p.pos + 1
+		templated, err := p.skipString()
+		if err != nil {
+			return attr, err
+		}
+		attr.end = p.pos - 1
+		attr.Raw = p.src[attr.start:attr.end]
+		p.skipSpaceAndComments(false)
+		if p.eof() || p.src[p.pos] == '\n' {
+			attr.Plain = !templated
+			return attr, nil
+		}
+	}
+	return attr, p.skipExpression()
+}
+
+func (p *tfvarsParser) skipString() (bool, error) {
+	templated := false
+	p.pos++
+	for !p.eof() {
+		c := p.src[p.pos]
+		switch {
+		case c == '\\':
+			p.pos += 2
+			continue
+		case c == '\n':
+			return false, p.errorf("unterminated string")
+		case c == '"':
+			p.pos++
+			return templated, nil
+		case strings.HasPrefix(p.src[p.pos:], "$${") || strings.HasPrefix(p.src[p.pos:], "%%{"):
+			p.pos += 3
+			continue
+		case strings.HasPrefix(p.src[p.pos:], "${") || strings.HasPrefix(p.src[p.pos:], "%{"):
+			templated = true
+			p.pos += 2
+			if err := p.skipUntilClose('}'); err != nil {
+				return false, err
+			}
+			continue
+		}
+		p.pos++
+	}
+	return false, p.errorf("unterminated string")
+}
+
+func (p *tfvarsParser) skipExpression() error {
+	for {
+		p.skipSpaceAndComments(false)
+		if p.eof() || p.src[p.pos] == '\n' {
+			return nil
+		}
+		if err := p.skipToken(); err != nil {
+			return err
+		}
+	}
+}
+
+func (p *tfvarsParser) skipUntilClose(closing byte) error {
+	line := p.line
+	for {
+		p.skipSpaceAndComments(true)
+		if p.eof() {
+			return fmt.Errorf("line %d: missing closing %q", line, closing)
+		}
+		if p.src[p.pos] == closing {
+			p.pos++
+			return nil
+		}
+		if err := p.skipToken(); err != nil {
+			return err
+		}
+	}
+}
+
+func (p *tfvarsParser) skipToken() error {
+	c := p.src[p.pos]
+	switch {
+	case c == '"':
+		_, err := p.skipString()
+		return err
+	case c == '{':
+		p.pos++
+		return p.skipUntilClose('}')
+	case c == '[':
+		p.pos++
+		return p.skipUntilClose(']')
+	case c == '(':
+		p.pos++
+		return p.skipUntilClose(')')
+	case strings.HasPrefix(p.src[p.pos:], "<<"):
+		return p.skipHeredoc()
+	}
+	p.pos++
+	return nil
+}
+
+func (p *tfvarsParser) skipHeredoc() error {
+	line := p.line
+	rest := strings.TrimPrefix(strings.TrimPrefix(p.src[p.pos:], "<<"), "-")
+	end := strings.IndexByte(rest, '\n')
+	if end < 0 {
+		return p.errorf("unterminated heredoc")
+	}
+	marker := strings.TrimSpace(rest[:end])
+	p.pos = len(p.src) - len(rest) + end + 1
+	p.line++
+	for !p.eof() {
+		next := strings.IndexByte(p.src[p.pos:], '\n')
+		lineText := p.src[p.pos:]
+		if next >= 0 {
+			lineText = p.src[p.pos : p.pos+next]
+		}
+		if strings.TrimSpace(lineText) == marker {
+			p.pos += len(lineText)
+			return nil
+		}
+		if next < 0 {
+			break
+		}
+		p.pos += next + 1
+		p.line++
+	}
+	return fmt.Errorf("line %d: heredoc %s is never closed", line, marker)
+}
+
+func isTfvarsNameChar(c byte, first bool) bool {
+	switch {
+	case c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z'):
+		return true
+	case first:
+		return false
+	default:
+		return c == '-' || (c >= '0' && c <= '9')
+	}
+}
@@ -0,0 +1,143 @@
+package domain
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type ValueLock struct {
+	Mode      string
+	Variables []string
+	Resign    bool
+}
+
+func IsValueLockMode(mode string) bool {
+	return mode == LockModeDotenvValues || mode == LockModeStructuredValues || mode == LockModeTfvarsValues
+}
+
+func LockValues(lock ValueLock, path string, data, key []byte) ([]byte, int, error) {
+	switch lock.Mode {
+	case LockModeDotenvValues:
+		return EncryptDotenvValues(data, key)
+	case LockModeStructuredValues:
+		return EncryptStructuredValues(path, data, key, lock.Resign)
+	case LockModeTfvarsValues:
+		return EncryptTfvarsValues(data, key, lock.Variables)
+	}
+	return nil, 0, fmt.Errorf("unsupported lock mode %q", lock.Mode)
+}
+
+func UnlockValues(mode, path string, data, key []byte) ([]byte, int, error) {
+	switch mode {
+	case LockModeDotenvValues:
+		return DecryptDotenvValues(data, key)
+	case LockModeStructuredValues:
+		return DecryptStructuredValues(path, data, key)
+	case LockModeTfvarsValues:
+		return DecryptTfvarsValues(data, key)
+	}
+	return nil, 0, fmt.Errorf("unsupported lock mode %q", mode)
+}
+
+func HasLockedValues(mode, path string, data []byte) bool {
+	switch mode {
+	case LockModeDotenvValues:
+		return HasDotenvTokens(data)
+	case LockModeStructuredValues:
+		return HasStructuredTokens(path, data)
+	case LockModeTfvarsValues:
+		return HasTfvarsTokens(data)
+	}
+	return false
+}
+
+func valueLockCandidate(path string) bool {
+	if !IsDotenvFile(path) && !IsStructuredLockFile(path) && !IsTfvarsFile(path) {
+		return false
+	}
+	_, skip := ContentScanSkipNames[strings.ToLower(filepath.Base(path))]
+	return !skip
+}
+
+// readValueLocked reads no more than the content scan would and returns those
+// bytes, so the caller can keep streaming from r when the file is not locked.
+func readValueLocked(path string, r io.Reader, opts ScanOptions) ([]byte, bool, error) {
+	limit := opts.MaxContentBytes
+	if limit <= 0 {
+		limit = DefaultMaxContentBytes
+	}
+	data, err := io.ReadAll(io.LimitReader(r, limit))
+	if err != nil {
+		return nil, false, err
+	}
+	return data, bytes.Contains(data, []byte(ValueTokenPrefix)) && isFullyValueLocked(path, data), nil
+}
+
+func isFullyValueLocked(path string, data []byte) bool {
+	if IsDotenvFile(path) {
+		return IsDotenvFullyEncrypted(data)
+	}
+	return IsStructuredLockFile(path) && bytes.Contains(data, []byte(ValueTokenPrefix)) && IsStructuredFullyEncrypted(path, data)
+}
+
+// maskTfvarsTokens blanks the sealed assignments so the content scan still
+// covers every attribute the configured variables left in plaintext.
+func maskTfvarsTokens(path string, data []byte) ([]byte, bool) {
+	if !IsTfvarsFile(path) || !bytes.Contains(data, []byte(ValueTokenPrefix)) {
+		return nil, false
+	}
+	file, err := ParseTfvars(data)
+	if err != nil {
+		return nil, false
+	}
+	masked := bytes.Clone(data)
+	found := false
+	for _, attr := range file.Attributes {
+		if !attr.Plain || !IsValueToken(attr.Raw) {
+			continue
+		}
+		lineStart := bytes.LastIndexByte(data[:attr.start], '\n') + 1
+		copy(masked[lineStart:attr.end], bytes.Repeat([]byte(" "), attr.end-lineStart))
+		found = true
+	}
+	return masked, found
+}
+
+func StructuredLockPatterns() []string {
+	return SplitLockPatterns(os.Getenv("SECRETVAULT_STRUCTURED_PATTERNS"))
+}
+
+func SplitLockPatterns(raw string) []string {
+	var out []string
+	for _, p := range strings.Split(raw, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func MatchesLockPattern(root, path string, patterns []string) bool {
+	if !IsStructuredLockFile(path) {
+		return false
+	}
+	base := filepath.Base(path)
+	rel := base
+	if r, err := filepath.Rel(root, path); err == nil {
+		rel = filepath.ToSlash(r)
+	}
+	for _, pattern := range patterns {
+		target := base
+		if strings.Contains(pattern, "/") {
+			target = rel
+		}
+		if ok, _ := filepath.Match(pattern, target); ok {
+			return true
+		}
+	}
+	return false
+}
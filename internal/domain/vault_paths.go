@@ -22,6 +22,14 @@ func VaultManifestPath(ctx ProjectContext) (string, error) {
 	return filepath.Join(projectDir, "manifest.json"), nil
 }
 
+func ScanIndexPath(ctx ProjectContext) (string, error) {
+	projectDir, err := VaultProjectPath(ctx)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(projectDir, "scan-index.json"), nil
+}
+
 func VaultProjectPath(ctx ProjectContext) (string, error) {
 	home, err := VaultHomeDir()
 	if err != nil {
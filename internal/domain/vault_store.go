@@ -3,6 +3,7 @@ package domain
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io/fs"
 	"os"
 	"path/filepath"
@@ -12,11 +13,27 @@ import (
 )
 
 func UpsertVaultEntry(ctx ProjectContext, originalPath, encryptedPath string, originalMode fs.FileMode) error {
-	absOriginal, err := filepath.Abs(originalPath)
+	absEncrypted, err := filepath.Abs(encryptedPath)
 	if err != nil {
 		return err
 	}
-	absEncrypted, err := filepath.Abs(encryptedPath)
+	payload, err := os.ReadFile(absEncrypted)
+	if err != nil {
+		return err
+	}
+	return upsertVaultEntryPayload(ctx, originalPath, absEncrypted, payload, originalMode, ValueLock{Mode: LockModeFile})
+}
+
+func UpsertValueLockedVaultEntry(ctx ProjectContext, path string, plaintext, key []byte, originalMode fs.FileMode, lock ValueLock) error {
+	payload, err := EncryptPayload(plaintext, key, originalMode)
+	if err != nil {
+		return err
+	}
+	return upsertVaultEntryPayload(ctx, path, "", payload, originalMode, lock)
+}
+
+func upsertVaultEntryPayload(ctx ProjectContext, originalPath, absEncrypted string, payload []byte, originalMode fs.FileMode, lock ValueLock) error {
+	absOriginal, err := filepath.Abs(originalPath)
 	if err != nil {
 		return err
 	}
@@ -36,10 +53,6 @@ func UpsertVaultEntry(ctx ProjectContext, originalPath, encryptedPath string, or
 		return err
 	}
 
-	payload, err := os.ReadFile(absEncrypted)
-	if err != nil {
-		return err
-	}
 	if err := WriteAtomic(vaultAbs, payload, 0o600); err != nil {
 		return err
 	}
@@ -60,12 +73,41 @@ func UpsertVaultEntry(ctx ProjectContext, originalPath, encryptedPath string, or
 		ProjectEncryptedFile: absEncrypted,
 		LockedAt:             now,
 		OriginalMode:         uint32(originalMode.Perm()),
+		LockMode:             lock.Mode,
+		LockVariables:        lock.Variables,
 	}
 	manifest.UpdatedAt = now
 
 	return SaveVaultManifest(manifestPath, manifest)
 }
 
+// UpdateEditedVaultEntry carries the restore and 1Password metadata of the
+// entry that was edited over to the re-locked one. The checksum keeps
+// describing the 1Password document until the file is absorbed again.
+func UpdateEditedVaultEntry(ctx ProjectContext, originalPath string, previous VaultEntry) error {
+	absOriginal, err := filepath.Abs(originalPath)
+	if err != nil {
+		return err
+	}
+	manifest, manifestPath, err := LoadVaultManifest(ctx)
+	if err != nil {
+		return err
+	}
+	entry, ok := manifest.Entries[absOriginal]
+	if !ok {
+		return fmt.Errorf("vault entry not found for %s", absOriginal)
+	}
+	entry.LastRestoredAt = previous.LastRestoredAt
+	entry.OnePasswordVault = previous.OnePasswordVault
+	entry.OnePasswordDocument = previous.OnePasswordDocument
+	entry.OnePasswordTitle = previous.OnePasswordTitle
+	entry.AbsorbedAt = previous.AbsorbedAt
+	entry.ChecksumSHA256 = previous.ChecksumSHA256
+	manifest.Entries[absOriginal] = entry
+	manifest.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+	return SaveVaultManifest(manifestPath, manifest)
+}
+
 func LoadVaultManifest(ctx ProjectContext) (VaultManifest, string, error) {
 	manifestPath, err := VaultManifestPath(ctx)
 	if err != nil {
@@ -132,7 +174,7 @@ func SelectRestoreEntries(ctx ProjectContext, manifest VaultManifest, args []str
 		for _, k := range keys {
 			entry := manifest.Entries[k]
 			target := ResolveEntryTargetPath(ctx, entry)
-			if restoreAll || !FileExists(target) {
+			if restoreAll || !FileExists(target) || EntryNeedsValueDecrypt(entry, target) {
 				out = append(out, entry)
 			}
 		}
@@ -220,3 +262,11 @@ func SortedVaultEntryKeys(manifest VaultManifest) []string {
 	sort.Strings(keys)
 	return keys
 }
+
+func EntryNeedsValueDecrypt(entry VaultEntry, target string) bool {
+	if !IsValueLockMode(entry.LockMode) {
+		return false
+	}
+	data, err := os.ReadFile(target)
+	return err == nil && HasLockedValues(entry.LockMode, target, data)
+}
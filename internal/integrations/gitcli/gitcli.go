@@ -0,0 +1,189 @@
+package gitcli
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const nullObjectID = "0000000000000000000000000000000000000000"
+
+type Commit struct {
+	Hash        string
+	Author      string
+	AuthorEmail string
+	Date        string
+}
+
+type Blob struct {
+	Commit   Commit
+	Path     string
+	ObjectID string
+	Status   string
+}
+
+func RepoRoot(path string) (string, error) {
+	dir := path
+	if info, err := os.Stat(path); err == nil && !info.IsDir() {
+		dir = filepath.Dir(path)
+	}
+	out, err := run(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", err
+	}
+	return filepath.Clean(strings.TrimSpace(string(out))), nil
+}
+
+func HistoryBlobs(repoRoot string, pathspecs []string) ([]Blob, error) {
+	args := []string{
+		"log", "--all", "--reverse", "-m", "--no-renames", "--diff-filter=AM",
+		"--raw", "--no-abbrev", "-z",
+		"--format=commit %H%x09%an%x09%ae%x09%aI",
+	}
+	if len(pathspecs) > 0 {
+		args = append(append(args, "--"), pathspecs...)
+	}
+	out, err := run(repoRoot, args...)
+	if err != nil {
+		return nil, err
+	}
+	return parseRawOutput(out)
+}
+
+func StagedBlobs(repoRoot string, pathspecs []string) ([]Blob, error) {
+	args := []string{"diff", "--cached", "--no-renames", "--diff-filter=AM", "--raw", "--no-abbrev", "-z"}
+	if len(pathspecs) > 0 {
+		args = append(append(args, "--"), pathspecs...)
+	}
+	out, err := run(repoRoot, args...)
+	if err != nil {
+		return nil, err
+	}
+	return parseRawOutput(out)
+}
+
+func parseRawOutput(out []byte) ([]Blob, error) {
+	var blobs []Blob
+	var commit Commit
+	tokens := strings.Split(string(out), "\x00")
+	for i := 0; i < len(tokens); i++ {
+		token := strings.TrimLeft(tokens[i], "\n")
+		switch {
+		case token == "":
+			continue
+		case strings.HasPrefix(token, "commit "):
+			fields := strings.SplitN(strings.TrimPrefix(token, "commit "), "\t", 4)
+			if len(fields) != 4 {
+				return nil, fmt.Errorf("unexpected git log header: %q", token)
+			}
+			commit = Commit{Hash: fields[0], Author: fields[1], AuthorEmail: fields[2], Date: fields[3]}
+		case strings.HasPrefix(token, ":"):
+			fields := strings.Fields(token)
+			if len(fields) < 5 || i+1 >= len(tokens) {
+				return nil, fmt.Errorf("unexpected git raw entry: %q", token)
+			}
+			i++
+			objectID := fields[3]
+			if objectID == nullObjectID || !strings.HasPrefix(fields[1], "100") {
+				continue
+			}
+			blobs = append(blobs, Blob{Commit: commit, Path: tokens[i], ObjectID: objectID, Status: fields[4]})
+		default:
+			return nil, fmt.Errorf("unexpected git output: %q", token)
+		}
+	}
+	return blobs, nil
+}
+
+type BlobReader struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout *bufio.Reader
+	stderr bytes.Buffer
+}
+
+func NewBlobReader(repoRoot string) (*BlobReader, error) {
+	r := &BlobReader{cmd: exec.Command("git", "cat-file", "--batch")}
+	r.cmd.Dir = repoRoot
+	r.cmd.Stderr = &r.stderr
+	stdin, err := r.cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := r.cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := r.cmd.Start(); err != nil {
+		return nil, fmt.Errorf("start git cat-file: %w", err)
+	}
+	r.stdin = stdin
+	r.stdout = bufio.NewReaderSize(stdout, 64*1024)
+	return r, nil
+}
+
+func (r *BlobReader) Read(objectID string, fn func(io.Reader) error) error {
+	if _, err := fmt.Fprintln(r.stdin, objectID); err != nil {
+		return r.wrap(err)
+	}
+	header, err := r.stdout.ReadString('\n')
+	if err != nil {
+		return r.wrap(err)
+	}
+	fields := strings.Fields(header)
+	if len(fields) == 2 && fields[1] == "missing" {
+		return fmt.Errorf("git object %s is missing", objectID)
+	}
+	if len(fields) != 3 {
+		return fmt.Errorf("unexpected git cat-file header: %q", strings.TrimSpace(header))
+	}
+	size, err := strconv.ParseInt(fields[2], 10, 64)
+	if err != nil {
+		return fmt.Errorf("unexpected git cat-file size: %q", fields[2])
+	}
+
+	body := io.LimitReader(r.stdout, size)
+	fnErr := fn(body)
+	if _, err := io.Copy(io.Discard, body); err != nil {
+		return r.wrap(err)
+	}
+	if _, err := r.stdout.Discard(1); err != nil {
+		return r.wrap(err)
+	}
+	return fnErr
+}
+
+func (r *BlobReader) Close() error {
+	r.stdin.Close()
+	return r.cmd.Wait()
+}
+
+func (r *BlobReader) wrap(err error) error {
+	if msg := strings.TrimSpace(r.stderr.String()); msg != "" {
+		return fmt.Errorf("git cat-file: %s", msg)
+	}
+	return fmt.Errorf("git cat-file: %w", err)
+}
+
+func run(dir string, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return nil, fmt.Errorf("git %s: %s", args[0], strings.TrimSpace(stderr.String()))
+		}
+		return nil, fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return out, nil
+}
@@ -0,0 +1,150 @@
+package gitcli
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseRawOutput(t *testing.T) {
+	out := "commit abc\tAlice\talice@example.com\t2024-01-02T03:04:05+00:00\x00" +
+		"\n:000000 100644 0000000000000000000000000000000000000000 1111111111111111111111111111111111111111 A\x00.env\x00" +
+		":000000 120000 0000000000000000000000000000000000000000 2222222222222222222222222222222222222222 A\x00link\x00" +
+		"commit def\tBob\tbob@example.com\t2024-02-02T03:04:05+00:00\x00" +
+		"\n:100644 100644 1111111111111111111111111111111111111111 3333333333333333333333333333333333333333 M\x00dir/a b.json\x00"
+
+	blobs, err := parseRawOutput([]byte(out))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(blobs) != 2 {
+		t.Fatalf("expected 2 blobs (symlink skipped), got %+v", blobs)
+	}
+	if blobs[0].Path != ".env" || blobs[0].Commit.Author != "Alice" || blobs[0].Status != "A" {
+		t.Fatalf("unexpected first blob: %+v", blobs[0])
+	}
+	if blobs[1].Path != "dir/a b.json" || blobs[1].Commit.Hash != "def" || blobs[1].ObjectID != "3333333333333333333333333333333333333333" {
+		t.Fatalf("unexpected second blob: %+v", blobs[1])
+	}
+}
+
+func TestHistoryBlobsAndBlobReader(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	gitRun := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=Tester", "-c", "user.email=tester@example.com"}, args...)...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+	gitRun("init", "-q")
+	if err := os.WriteFile(filepath.Join(dir, ".env"), []byte("TOKEN=one\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	gitRun("add", ".env")
+	gitRun("commit", "-q", "-m", "add env")
+	gitRun("rm", "-q", ".env")
+	gitRun("commit", "-q", "-m", "remove env")
+
+	root, err := RepoRoot(dir)
+	if err != nil {
+		t.Fatalf("repo root: %v", err)
+	}
+	blobs, err := HistoryBlobs(root, nil)
+	if err != nil {
+		t.Fatalf("history: %v", err)
+	}
+	if len(blobs) != 1 || blobs[0].Path != ".env" || blobs[0].Commit.Author != "Tester" {
+		t.Fatalf("unexpected history blobs: %+v", blobs)
+	}
+
+	reader, err := NewBlobReader(root)
+	if err != nil {
+		t.Fatalf("blob reader: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		var got []byte
+		err := reader.Read(blobs[0].ObjectID, func(r io.Reader) error {
+			var err error
+			got, err = io.ReadAll(r)
+			return err
+		})
+		if err != nil {
+			t.Fatalf("read blob: %v", err)
+		}
+		if string(got) != "TOKEN=one\n" {
+			t.Fatalf("unexpected blob content %q", got)
+		}
+	}
+	if err := reader.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+}
+
+func TestHistoryBlobsIncludeMergeResolutions(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	gitRun := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=Tester", "-c", "user.email=tester@example.com"}, args...)...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil && args[0] != "merge" {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+	write := func(data string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, "config.ini"), []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	gitRun("init", "-q", "-b", "main")
+	write("mode=base\n")
+	gitRun("add", ".")
+	gitRun("commit", "-q", "-m", "base")
+	gitRun("checkout", "-q", "-b", "feature")
+	write("mode=feature\n")
+	gitRun("commit", "-q", "-am", "feature")
+	gitRun("checkout", "-q", "main")
+	write("mode=main\n")
+	gitRun("commit", "-q", "-am", "main")
+	gitRun("merge", "-q", "feature")
+	write("mode=merged\npassword=resolved-in-merge\n")
+	gitRun("commit", "-q", "-am", "merge feature")
+
+	root, err := RepoRoot(dir)
+	if err != nil {
+		t.Fatalf("repo root: %v", err)
+	}
+	blobs, err := HistoryBlobs(root, nil)
+	if err != nil {
+		t.Fatalf("history: %v", err)
+	}
+	reader, err := NewBlobReader(root)
+	if err != nil {
+		t.Fatalf("blob reader: %v", err)
+	}
+	defer reader.Close()
+	found := false
+	for _, blob := range blobs {
+		err := reader.Read(blob.ObjectID, func(r io.Reader) error {
+			data, err := io.ReadAll(r)
+			found = found || string(data) == "mode=merged\npassword=resolved-in-merge\n"
+			return err
+		})
+		if err != nil {
+			t.Fatalf("read blob: %v", err)
+		}
+	}
+	if !found {
+		t.Fatalf("expected the merge resolution blob in %+v", blobs)
+	}
+}
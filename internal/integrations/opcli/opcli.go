@@ -1,6 +1,7 @@
 package opcli
 
 import (
+	"bytes"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
@@ -89,6 +90,22 @@ func UploadFileWithMetadata(path, vaultName, title string, metadata DocumentMeta
 	return id, nil
 }
 
+func ReplaceDocument(entry domain.VaultEntry, path string) error {
+	if err := ensureReady(); err != nil {
+		return err
+	}
+	args := []string{"document", "edit", entry.OnePasswordDocument, path}
+	if strings.TrimSpace(entry.OnePasswordVault) != "" {
+		args = append(args, "--vault", entry.OnePasswordVault)
+	}
+	cmd := exec.Command("op", args...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("op document edit failed: %v (%s)", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
 func DeleteDocument(documentID, vaultName string) error {
 	if strings.TrimSpace(documentID) == "" {
 		return fmt.Errorf("missing 1password document id")
@@ -129,16 +146,9 @@ func ExtractDocumentID(out []byte) string {
 }
 
 func RestoreDocument(entry domain.VaultEntry, targetPath string, mode fs.FileMode, force bool) error {
-	if !HasCommand("op") {
-		return fmt.Errorf("1Password CLI (op) is not installed")
-	}
-	authed, err := IsAuthenticated()
-	if err != nil {
+	if err := ensureReady(); err != nil {
 		return err
 	}
-	if !authed {
-		return fmt.Errorf("1Password CLI is not authenticated")
-	}
 
 	if domain.FileExists(targetPath) {
 		if !force {
@@ -168,6 +178,38 @@ func RestoreDocument(entry domain.VaultEntry, targetPath string, mode fs.FileMod
 	return os.Chmod(targetPath, mode)
 }
 
+func ReadDocument(entry domain.VaultEntry) ([]byte, error) {
+	if err := ensureReady(); err != nil {
+		return nil, err
+	}
+	args := []string{"document", "get", entry.OnePasswordDocument}
+	if strings.TrimSpace(entry.OnePasswordVault) != "" {
+		args = append(args, "--vault", entry.OnePasswordVault)
+	}
+	var stderr bytes.Buffer
+	cmd := exec.Command("op", args...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("op document get failed: %v (%s)", err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+func ensureReady() error {
+	if !HasCommand("op") {
+		return fmt.Errorf("1Password CLI (op) is not installed")
+	}
+	authed, err := IsAuthenticated()
+	if err != nil {
+		return err
+	}
+	if !authed {
+		return fmt.Errorf("1Password CLI is not authenticated")
+	}
+	return nil
+}
+
 func AnnotateVaultEntry(ctx domain.ProjectContext, originalPath, vaultName, documentID, title, checksum string) error {
 	absOriginal, err := filepath.Abs(originalPath)
 	if err != nil {
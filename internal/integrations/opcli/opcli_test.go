@@ -1,7 +1,10 @@
 package opcli
 
 import (
+	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
 
 	"secrets-vault/internal/domain"
@@ -47,6 +50,38 @@ func TestBuildDocumentMetadata(t *testing.T) {
 	}
 }
 
+func TestReadDocumentPassesVaultAndReportsStderr(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake op is a shell script")
+	}
+	bin := t.TempDir()
+	script := `#!/bin/sh
+case "$1 $2" in
+"account list") echo '[{"url":"example.1password.com"}]' ;;
+"document get")
+	if [ "$3" = "doc-1" ] && [ "$4" = "--vault" ] && [ "$5" = "Private" ]; then
+		printf 'TOKEN=abc\n'
+	else
+		echo "\"$3\" isn't a document" >&2
+		exit 1
+	fi ;;
+esac
+`
+	if err := os.WriteFile(filepath.Join(bin, "op"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin)
+
+	got, err := ReadDocument(domain.VaultEntry{OnePasswordDocument: "doc-1", OnePasswordVault: "Private"})
+	if err != nil || string(got) != "TOKEN=abc\n" {
+		t.Fatalf("expected document content, got %q err=%v", got, err)
+	}
+	_, err = ReadDocument(domain.VaultEntry{OnePasswordDocument: "doc-2"})
+	if err == nil || !strings.Contains(err.Error(), `"doc-2" isn't a document`) {
+		t.Fatalf("expected op stderr in the error, got %v", err)
+	}
+}
+
 func containsCSVTag(csv, want string) bool {
 	parts := splitCSV(csv)
 	for _, part := range parts {